@@ -0,0 +1,111 @@
+package schnorrkel
+
+import (
+	"context"
+	"errors"
+)
+
+// DefaultGapLimit is the BIP-44 gap limit, the number of consecutive unused accounts after
+// which ScanAccounts stops
+const DefaultGapLimit = 20
+
+// Account is a soft-derived child of an extended public key found by ScanAccounts
+type Account struct {
+	Index     uint64
+	Path      DerivationPath
+	PublicKey *PublicKey
+	Address   string
+}
+
+// ScanAccounts derives the soft children /0, /1, ... of the extended key's public key and
+// returns those for which isUsed returns true, in index order. Scanning stops once gapLimit
+// consecutive children are unused, as in BIP-44. Only the public key of the extended key is
+// used, so it can be an extended public key given to a watch-only service. The children are
+// derived as by DerivePath, so the addresses are those subkey gives for the same paths. The paths
+// of the accounts are relative to the extended key, and their addresses use the SS58 prefix.
+func ScanAccounts(ctx context.Context, ek *ExtendedKey, gapLimit int, prefix uint16,
+	isUsed func(*Account) (bool, error)) ([]*Account, error) {
+	if ctx == nil {
+		return nil, errors.New("context provided is nil")
+	}
+
+	if ek == nil {
+		return nil, errors.New("extended key provided is nil")
+	}
+
+	if gapLimit < 1 {
+		return nil, errors.New("gap limit must be at least 1")
+	}
+
+	if isUsed == nil {
+		return nil, errors.New("is used predicate provided is nil")
+	}
+
+	if prefix > MaxSS58Prefix {
+		return nil, ErrInvalidSS58Prefix
+	}
+
+	// expand a mini secret key as DerivePath does, before its public key is computed
+	key := ek.key
+	if msk, ok := key.(*MiniSecretKey); ok {
+		key = msk.ExpandEd25519()
+	}
+
+	pub, err := NewExtendedKey(key, ek.chaincode).Public()
+	if err != nil {
+		return nil, err
+	}
+	parent := NewExtendedKey(pub, ek.chaincode)
+
+	accounts := []*Account{}
+	for index, gap := uint64(0), 0; gap < gapLimit; index++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		account, err := deriveAccount(parent, index, prefix)
+		if err != nil {
+			return nil, err
+		}
+
+		used, err := isUsed(account)
+		if err != nil {
+			return nil, err
+		}
+
+		if !used {
+			gap++
+			continue
+		}
+
+		gap = 0
+		accounts = append(accounts, account)
+	}
+
+	return accounts, nil
+}
+
+func deriveAccount(parent *ExtendedKey, index uint64, prefix uint16) (*Account, error) {
+	path := DerivationPath{NewNumericJunction(index, false)}
+	child, err := parent.DerivePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	pub, err := child.Public()
+	if err != nil {
+		return nil, err
+	}
+
+	address, err := pub.SS58Address(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Account{
+		Index:     index,
+		Path:      path,
+		PublicKey: pub,
+		Address:   address,
+	}, nil
+}
@@ -0,0 +1,120 @@
+package schnorrkel
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newScanTestKey(t *testing.T) (*ExtendedKey, map[string]uint64) {
+	priv, _, err := GenerateKeypair()
+	require.NoError(t, err)
+	root := NewExtendedKey(priv, [ChainCodeLength]byte{4})
+
+	// the addresses of the accounts in use, derived from the secret key
+	used := make(map[string]uint64)
+	for _, index := range []uint64{0, 3, 25} {
+		child, err := root.DerivePath(DerivationPath{NewNumericJunction(index, false)})
+		require.NoError(t, err)
+		pub, err := child.Public()
+		require.NoError(t, err)
+		address, err := pub.SS58Address(SubstrateSS58Prefix)
+		require.NoError(t, err)
+		used[address] = index
+	}
+
+	return root, used
+}
+
+func TestScanAccounts(t *testing.T) {
+	root, used := newScanTestKey(t)
+	xpub, err := root.Neuter()
+	require.NoError(t, err)
+
+	isUsed := func(a *Account) (bool, error) {
+		_, has := used[a.Address]
+		return has, nil
+	}
+
+	// index 25 follows the 21 unused accounts 4 to 24
+	accounts, err := ScanAccounts(context.Background(), xpub, DefaultGapLimit, SubstrateSS58Prefix, isUsed)
+	require.NoError(t, err)
+	require.Len(t, accounts, 2)
+	require.Equal(t, uint64(0), accounts[0].Index)
+	require.Equal(t, uint64(3), accounts[1].Index)
+	require.Equal(t, "/3", accounts[1].Path.String())
+
+	accounts, err = ScanAccounts(context.Background(), xpub, 22, SubstrateSS58Prefix, isUsed)
+	require.NoError(t, err)
+	require.Len(t, accounts, 3)
+	for _, a := range accounts {
+		require.Equal(t, used[a.Address], a.Index)
+		address, err := a.PublicKey.SS58Address(SubstrateSS58Prefix)
+		require.NoError(t, err)
+		require.Equal(t, a.Address, address)
+	}
+}
+
+func TestScanAccounts_Subkey(t *testing.T) {
+	// the mini secret key of TestExtendedKey_DerivePath_Subkey, and the address of
+	// //foo/bar//42/69 given by subkey
+	msk, err := NewMiniSecretKeyFromHex("0x18446f2d685492c3086391aabe8f5e235c3c2e02521985650f0c97052237e717")
+	require.NoError(t, err)
+	path, err := ParseDerivationPath("//foo/bar//42")
+	require.NoError(t, err)
+	parent, err := NewExtendedKey(msk, [ChainCodeLength]byte{}).DerivePath(path)
+	require.NoError(t, err)
+	xpub, err := parent.Neuter()
+	require.NoError(t, err)
+
+	accounts, err := ScanAccounts(context.Background(), xpub, 70, SubstrateSS58Prefix, func(a *Account) (bool, error) {
+		return a.Index == 69, nil
+	})
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+	require.Equal(t, "/69", accounts[0].Path.String())
+	require.Equal(t, "5ERv3mLP7CX1CViNc6NUQaePBJMkf6BELffpMfXjXjj28SNo", accounts[0].Address)
+
+	// a mini secret key root is expanded as by DerivePath
+	accounts, err = ScanAccounts(context.Background(), NewExtendedKey(msk, [ChainCodeLength]byte{}), 1,
+		SubstrateSS58Prefix, func(a *Account) (bool, error) {
+			return a.Index == 0, nil
+		})
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+	expected, err := NewExtendedKey(msk, [ChainCodeLength]byte{}).DerivePath(DerivationPath{NewNumericJunction(0, false)})
+	require.NoError(t, err)
+	expectedPub, err := expected.Public()
+	require.NoError(t, err)
+	require.Equal(t, expectedPub.Encode(), accounts[0].PublicKey.Encode())
+}
+
+func TestScanAccounts_Errors(t *testing.T) {
+	root, _ := newScanTestKey(t)
+
+	errLookup := errors.New("lookup failed")
+	_, err := ScanAccounts(context.Background(), root, DefaultGapLimit, SubstrateSS58Prefix, func(*Account) (bool, error) {
+		return false, errLookup
+	})
+	require.ErrorIs(t, err, errLookup)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = ScanAccounts(ctx, root, DefaultGapLimit, SubstrateSS58Prefix, func(*Account) (bool, error) {
+		return true, nil
+	})
+	require.ErrorIs(t, err, context.Canceled)
+
+	_, err = ScanAccounts(context.Background(), root, 0, SubstrateSS58Prefix, func(*Account) (bool, error) {
+		return false, nil
+	})
+	require.Error(t, err)
+
+	var nilCtx context.Context
+	_, err = ScanAccounts(nilCtx, root, DefaultGapLimit, SubstrateSS58Prefix, func(*Account) (bool, error) {
+		return false, nil
+	})
+	require.Error(t, err)
+}
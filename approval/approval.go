@@ -0,0 +1,335 @@
+// Package approval implements the VRF assignment criteria of Polkadot's parachain approval
+// voting: RelayVRFModulo and RelayVRFDelay assignment certificates, their creation and their
+// verification.
+// see: https://github.com/paritytech/polkadot/blob/master/node/core/approval-voting/src/criteria.rs
+package approval
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+
+	"github.com/ChainSafe/go-schnorrkel"
+	"github.com/gtank/merlin"
+)
+
+// VRF transcript labels and MakeBytes contexts used by approval voting
+const (
+	RelayVRFStoryContext     = "A&V RC-VRF"
+	RelayVRFModuloContext    = "A&V MOD"
+	RelayVRFDelayContext     = "A&V DELAY"
+	AssignedCoreContext      = "A&V ASSIGNED"
+	CoreRandomnessContext    = "A&V CORE"
+	TrancheRandomnessContext = "A&V TRANCHE"
+)
+
+var (
+	ErrInvalidConfig              = errors.New("invalid assignment criteria config")
+	ErrSampleOutOfBounds          = errors.New("relay VRF modulo sample out of bounds")
+	ErrVRFModuloCoreIndexMismatch = errors.New("relay VRF modulo output does not give the claimed core")
+	ErrVRFModuloOutputMismatch    = errors.New("relay VRF modulo proof is invalid")
+	ErrVRFDelayCoreIndexMismatch  = errors.New("relay VRF delay certificate is for another core")
+	ErrVRFDelayOutputMismatch     = errors.New("relay VRF delay proof is invalid")
+)
+
+// RelayVRFStory is the randomness of a relay chain block that assignments are derived from
+type RelayVRFStory [32]byte
+
+// Config holds the session parameters of the assignment criteria
+type Config struct {
+	NCores                  uint32
+	RelayVRFModuloSamples   uint32
+	NDelayTranches          uint32
+	ZerothDelayTrancheWidth uint32
+}
+
+// CertKind is the kind of an assignment certificate, either RelayVRFModulo or RelayVRFDelay
+type CertKind interface {
+	isCertKind()
+}
+
+// RelayVRFModulo is an assignment to a core selected by the VRF output of the given sample
+type RelayVRFModulo struct {
+	Sample uint32
+}
+
+// RelayVRFDelay is an assignment to a core with a delay tranche selected by the VRF output
+type RelayVRFDelay struct {
+	CoreIndex uint32
+}
+
+func (RelayVRFModulo) isCertKind() {}
+func (RelayVRFDelay) isCertKind()  {}
+
+// AssignmentCert is a certificate proving a validator's assignment to check a candidate
+type AssignmentCert struct {
+	Kind   CertKind
+	Output *schnorrkel.VrfOutput
+	Proof  *schnorrkel.VrfProof
+}
+
+// Assignment is one of our assignments, for a core in a delay tranche
+type Assignment struct {
+	Cert      *AssignmentCert
+	CoreIndex uint32
+	Tranche   uint32
+}
+
+// NewRelayVRFStory returns the relay VRF story from the BABE VRF input and output of a
+// relay chain block
+func NewRelayVRFStory(babeInOut *schnorrkel.VrfInOut) (RelayVRFStory, error) {
+	if babeInOut == nil {
+		return RelayVRFStory{}, errors.New("input and output provided is nil")
+	}
+
+	b, err := babeInOut.MakeBytes(32, []byte(RelayVRFStoryContext))
+	if err != nil {
+		return RelayVRFStory{}, err
+	}
+
+	story := RelayVRFStory{}
+	copy(story[:], b)
+	return story, nil
+}
+
+func (c Config) validate() error {
+	// the tranche randomness is reduced modulo the sum of the tranche widths, as a u32
+	width := uint64(c.NDelayTranches) + uint64(c.ZerothDelayTrancheWidth)
+	if c.NCores == 0 || width == 0 || width > math.MaxUint32 {
+		return ErrInvalidConfig
+	}
+	return nil
+}
+
+func appendUint32(t *merlin.Transcript, label []byte, v uint32) {
+	b := [4]byte{}
+	binary.LittleEndian.PutUint32(b[:], v)
+	t.AppendMessage(label, b[:])
+}
+
+func relayVRFModuloTranscript(story RelayVRFStory, sample uint32) *merlin.Transcript {
+	t := merlin.NewTranscript(RelayVRFModuloContext)
+	t.AppendMessage([]byte("RC-VRF"), story[:])
+	appendUint32(t, []byte("sample"), sample)
+	return t
+}
+
+func relayVRFDelayTranscript(story RelayVRFStory, core uint32) *merlin.Transcript {
+	t := merlin.NewTranscript(RelayVRFDelayContext)
+	t.AppendMessage([]byte("RC-VRF"), story[:])
+	appendUint32(t, []byte("core"), core)
+	return t
+}
+
+func assignedCoreTranscript(core uint32) *merlin.Transcript {
+	t := merlin.NewTranscript(AssignedCoreContext)
+	appendUint32(t, []byte("core"), core)
+	return t
+}
+
+func makeUint32(inout *schnorrkel.VrfInOut, context string) (uint32, error) {
+	b, err := inout.MakeBytes(4, []byte(context))
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint32(b), nil
+}
+
+func relayVRFModuloCore(inout *schnorrkel.VrfInOut, nCores uint32) (uint32, error) {
+	r, err := makeUint32(inout, CoreRandomnessContext)
+	if err != nil {
+		return 0, err
+	}
+	return r % nCores, nil
+}
+
+func relayVRFDelayTranche(inout *schnorrkel.VrfInOut, nDelayTranches, zerothDelayTrancheWidth uint32) (uint32, error) {
+	r, err := makeUint32(inout, TrancheRandomnessContext)
+	if err != nil {
+		return 0, err
+	}
+
+	// early results are consolidated into tranche zero, so that tranche zero is extra wide
+	wide := r % (nDelayTranches + zerothDelayTrancheWidth)
+	if wide < zerothDelayTrancheWidth {
+		return 0, nil
+	}
+	return wide - zerothDelayTrancheWidth, nil
+}
+
+// ComputeAssignments returns our assignments for the cores with candidates leaving
+// availability, keyed by core. RelayVRFModulo assignments, which are always in tranche
+// zero, take precedence over RelayVRFDelay assignments for the same core.
+func ComputeAssignments(kp *schnorrkel.Keypair, config Config, story RelayVRFStory, leavingCores []uint32) (map[uint32]*Assignment, error) {
+	modulo, err := ComputeRelayVRFModuloAssignments(kp, config, story, leavingCores)
+	if err != nil {
+		return nil, err
+	}
+
+	delay, err := ComputeRelayVRFDelayAssignments(kp, config, story, leavingCores)
+	if err != nil {
+		return nil, err
+	}
+
+	assignments := make(map[uint32]*Assignment)
+	for _, a := range append(modulo, delay...) {
+		if _, ok := assignments[a.CoreIndex]; !ok {
+			assignments[a.CoreIndex] = a
+		}
+	}
+	return assignments, nil
+}
+
+// ComputeRelayVRFModuloAssignments returns the RelayVRFModulo assignments for the cores with
+// candidates leaving availability. Proofs are only created for samples that select one of them.
+func ComputeRelayVRFModuloAssignments(kp *schnorrkel.Keypair, config Config, story RelayVRFStory, leavingCores []uint32) ([]*Assignment, error) {
+	if kp == nil {
+		return nil, errors.New("keypair provided is nil")
+	}
+
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	leaving := make(map[uint32]bool, len(leavingCores))
+	for _, core := range leavingCores {
+		leaving[core] = true
+	}
+
+	assignments := []*Assignment{}
+	for sample := uint32(0); sample < config.RelayVRFModuloSamples; sample++ {
+		var (
+			core     uint32
+			checkErr error
+		)
+
+		inout, proof, err := kp.VrfSignExtraAfterCheck(relayVRFModuloTranscript(story, sample), func(inout *schnorrkel.VrfInOut) *merlin.Transcript {
+			core, checkErr = relayVRFModuloCore(inout, config.NCores)
+			if checkErr != nil || !leaving[core] {
+				return nil
+			}
+			return assignedCoreTranscript(core)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if checkErr != nil {
+			return nil, checkErr
+		}
+
+		if proof == nil {
+			continue
+		}
+
+		assignments = append(assignments, &Assignment{
+			Cert: &AssignmentCert{
+				Kind:   RelayVRFModulo{Sample: sample},
+				Output: inout.Output(),
+				Proof:  proof,
+			},
+			CoreIndex: core,
+			Tranche:   0,
+		})
+	}
+
+	return assignments, nil
+}
+
+// ComputeRelayVRFDelayAssignments returns a RelayVRFDelay assignment for each of the cores with
+// candidates leaving availability
+func ComputeRelayVRFDelayAssignments(kp *schnorrkel.Keypair, config Config, story RelayVRFStory, leavingCores []uint32) ([]*Assignment, error) {
+	if kp == nil {
+		return nil, errors.New("keypair provided is nil")
+	}
+
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	assignments := make([]*Assignment, len(leavingCores))
+	for i, core := range leavingCores {
+		inout, proof, err := kp.VrfSign(relayVRFDelayTranscript(story, core))
+		if err != nil {
+			return nil, err
+		}
+
+		tranche, err := relayVRFDelayTranche(inout, config.NDelayTranches, config.ZerothDelayTrancheWidth)
+		if err != nil {
+			return nil, err
+		}
+
+		assignments[i] = &Assignment{
+			Cert: &AssignmentCert{
+				Kind:   RelayVRFDelay{CoreIndex: core},
+				Output: inout.Output(),
+				Proof:  proof,
+			},
+			CoreIndex: core,
+			Tranche:   tranche,
+		}
+	}
+
+	return assignments, nil
+}
+
+// CheckAssignmentCert verifies the assignment certificate of the validator with the given public
+// key for the claimed core, and returns the delay tranche of the assignment
+func CheckAssignmentCert(pub *schnorrkel.PublicKey, config Config, story RelayVRFStory, claimedCore uint32, cert *AssignmentCert) (uint32, error) {
+	if pub == nil {
+		return 0, errors.New("public key provided is nil")
+	}
+
+	if cert == nil || cert.Output == nil || cert.Proof == nil {
+		return 0, errors.New("assignment certificate provided is incomplete")
+	}
+
+	if err := config.validate(); err != nil {
+		return 0, err
+	}
+
+	switch kind := cert.Kind.(type) {
+	case RelayVRFModulo:
+		if kind.Sample >= config.RelayVRFModuloSamples {
+			return 0, ErrSampleOutOfBounds
+		}
+
+		inout, ok, err := pub.VrfVerifyExtraInOut(relayVRFModuloTranscript(story, kind.Sample), cert.Output, cert.Proof,
+			assignedCoreTranscript(claimedCore))
+		if err != nil {
+			return 0, err
+		}
+
+		if !ok {
+			return 0, ErrVRFModuloOutputMismatch
+		}
+
+		core, err := relayVRFModuloCore(inout, config.NCores)
+		if err != nil {
+			return 0, err
+		}
+
+		if core != claimedCore {
+			return 0, ErrVRFModuloCoreIndexMismatch
+		}
+
+		return 0, nil
+	case RelayVRFDelay:
+		if kind.CoreIndex != claimedCore {
+			return 0, ErrVRFDelayCoreIndexMismatch
+		}
+
+		inout, ok, err := pub.VrfVerifyInOut(relayVRFDelayTranscript(story, kind.CoreIndex), cert.Output, cert.Proof)
+		if err != nil {
+			return 0, err
+		}
+
+		if !ok {
+			return 0, ErrVRFDelayOutputMismatch
+		}
+
+		return relayVRFDelayTranche(inout, config.NDelayTranches, config.ZerothDelayTrancheWidth)
+	default:
+		return 0, errors.New("unknown assignment certificate kind")
+	}
+}
@@ -0,0 +1,168 @@
+package approval
+
+import (
+	"testing"
+
+	"github.com/ChainSafe/go-schnorrkel"
+	"github.com/gtank/merlin"
+	"github.com/stretchr/testify/require"
+)
+
+var testConfig = Config{
+	NCores:                  4,
+	RelayVRFModuloSamples:   6,
+	NDelayTranches:          40,
+	ZerothDelayTrancheWidth: 5,
+}
+
+func newTestKeypair(t *testing.T) (*schnorrkel.Keypair, *schnorrkel.PublicKey) {
+	priv, pub, err := schnorrkel.GenerateKeypair()
+	require.NoError(t, err)
+	return schnorrkel.NewKeypair(pub, priv), pub
+}
+
+func newTestStory(t *testing.T) RelayVRFStory {
+	kp, _ := newTestKeypair(t)
+	inout, _, err := kp.VrfSign(merlin.NewTranscript("BABE"))
+	require.NoError(t, err)
+
+	story, err := NewRelayVRFStory(inout)
+	require.NoError(t, err)
+
+	expected, err := inout.MakeBytes(32, []byte(RelayVRFStoryContext))
+	require.NoError(t, err)
+	require.Equal(t, expected, story[:])
+	return story
+}
+
+func TestRelayVRFModuloAssignments(t *testing.T) {
+	kp, pub := newTestKeypair(t)
+	story := newTestStory(t)
+	leaving := []uint32{0, 1, 2, 3}
+
+	assignments, err := ComputeRelayVRFModuloAssignments(kp, testConfig, story, leaving)
+	require.NoError(t, err)
+	// every core is leaving availability, so every sample gives an assignment
+	require.Len(t, assignments, int(testConfig.RelayVRFModuloSamples))
+
+	for i, a := range assignments {
+		require.Equal(t, RelayVRFModulo{Sample: uint32(i)}, a.Cert.Kind)
+		require.Zero(t, a.Tranche)
+
+		tranche, err := CheckAssignmentCert(pub, testConfig, story, a.CoreIndex, a.Cert)
+		require.NoError(t, err)
+		require.Zero(t, tranche)
+
+		// the proof commits to the claimed core
+		_, err = CheckAssignmentCert(pub, testConfig, story, (a.CoreIndex+1)%testConfig.NCores, a.Cert)
+		require.ErrorIs(t, err, ErrVRFModuloOutputMismatch)
+
+		_, err = CheckAssignmentCert(pub, testConfig, RelayVRFStory{}, a.CoreIndex, a.Cert)
+		require.ErrorIs(t, err, ErrVRFModuloOutputMismatch)
+	}
+
+	config := testConfig
+	config.RelayVRFModuloSamples = 1
+	_, err = CheckAssignmentCert(pub, config, story, assignments[1].CoreIndex, assignments[1].Cert)
+	require.ErrorIs(t, err, ErrSampleOutOfBounds)
+
+	// no proofs are created for samples selecting cores without candidates
+	assignments, err = ComputeRelayVRFModuloAssignments(kp, testConfig, story, nil)
+	require.NoError(t, err)
+	require.Empty(t, assignments)
+}
+
+func TestRelayVRFModuloAssignments_CoreIndexMismatch(t *testing.T) {
+	kp, pub := newTestKeypair(t)
+	story := newTestStory(t)
+
+	assignments, err := ComputeRelayVRFModuloAssignments(kp, testConfig, story, []uint32{0, 1, 2, 3})
+	require.NoError(t, err)
+
+	// a valid proof for the claimed core, whose output selects another core
+	a := assignments[0]
+	claimed := (a.CoreIndex + 1) % testConfig.NCores
+	inout, proof, err := kp.VrfSignExtra(relayVRFModuloTranscript(story, 0), assignedCoreTranscript(claimed))
+	require.NoError(t, err)
+
+	cert := &AssignmentCert{Kind: RelayVRFModulo{Sample: 0}, Output: inout.Output(), Proof: proof}
+	_, err = CheckAssignmentCert(pub, testConfig, story, claimed, cert)
+	require.ErrorIs(t, err, ErrVRFModuloCoreIndexMismatch)
+}
+
+func TestRelayVRFDelayAssignments(t *testing.T) {
+	kp, pub := newTestKeypair(t)
+	story := newTestStory(t)
+	leaving := []uint32{1, 3}
+
+	assignments, err := ComputeRelayVRFDelayAssignments(kp, testConfig, story, leaving)
+	require.NoError(t, err)
+	require.Len(t, assignments, len(leaving))
+
+	for i, a := range assignments {
+		require.Equal(t, leaving[i], a.CoreIndex)
+		require.Equal(t, RelayVRFDelay{CoreIndex: leaving[i]}, a.Cert.Kind)
+		require.Less(t, a.Tranche, testConfig.NDelayTranches)
+
+		tranche, err := CheckAssignmentCert(pub, testConfig, story, a.CoreIndex, a.Cert)
+		require.NoError(t, err)
+		require.Equal(t, a.Tranche, tranche)
+
+		_, err = CheckAssignmentCert(pub, testConfig, story, a.CoreIndex+1, a.Cert)
+		require.ErrorIs(t, err, ErrVRFDelayCoreIndexMismatch)
+
+		_, err = CheckAssignmentCert(pub, testConfig, RelayVRFStory{}, a.CoreIndex, a.Cert)
+		require.ErrorIs(t, err, ErrVRFDelayOutputMismatch)
+	}
+}
+
+func TestComputeAssignments(t *testing.T) {
+	kp, pub := newTestKeypair(t)
+	story := newTestStory(t)
+	leaving := []uint32{0, 1, 2, 3}
+
+	assignments, err := ComputeAssignments(kp, testConfig, story, leaving)
+	require.NoError(t, err)
+	require.Len(t, assignments, len(leaving))
+
+	modulo, err := ComputeRelayVRFModuloAssignments(kp, testConfig, story, leaving)
+	require.NoError(t, err)
+
+	for core, a := range assignments {
+		require.Equal(t, core, a.CoreIndex)
+
+		tranche, err := CheckAssignmentCert(pub, testConfig, story, core, a.Cert)
+		require.NoError(t, err)
+		require.Equal(t, a.Tranche, tranche)
+	}
+
+	for _, m := range modulo {
+		require.IsType(t, RelayVRFModulo{}, assignments[m.CoreIndex].Cert.Kind)
+	}
+}
+
+func TestRelayVRFDelayTranche(t *testing.T) {
+	kp, _ := newTestKeypair(t)
+	for core := uint32(0); core < 16; core++ {
+		inout, err := kp.VrfCreateHash(relayVRFDelayTranscript(RelayVRFStory{}, core))
+		require.NoError(t, err)
+
+		wide, err := makeUint32(inout, TrancheRandomnessContext)
+		require.NoError(t, err)
+		wide %= testConfig.NDelayTranches + testConfig.ZerothDelayTrancheWidth
+
+		tranche, err := relayVRFDelayTranche(inout, testConfig.NDelayTranches, testConfig.ZerothDelayTrancheWidth)
+		require.NoError(t, err)
+		if wide < testConfig.ZerothDelayTrancheWidth {
+			require.Zero(t, tranche)
+		} else {
+			require.Equal(t, wide-testConfig.ZerothDelayTrancheWidth, tranche)
+		}
+	}
+}
+
+func TestInvalidConfig(t *testing.T) {
+	kp, _ := newTestKeypair(t)
+	_, err := ComputeAssignments(kp, Config{}, RelayVRFStory{}, []uint32{0})
+	require.ErrorIs(t, err, ErrInvalidConfig)
+}
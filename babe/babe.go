@@ -0,0 +1,181 @@
+// Package babe implements the sr25519 VRF parts of the BABE block production protocol
+// used by Substrate: the slot transcript, the stake-weighted primary slot threshold,
+// and primary slot claims and their verification.
+package babe
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	"github.com/ChainSafe/go-schnorrkel"
+	"github.com/gtank/merlin"
+)
+
+const (
+	// EngineID is the BABE consensus engine id, also used as the VRF transcript label
+	EngineID = "BABE"
+
+	// VRFPrefix is the context used to extract the primary slot threshold bytes from a VRF output
+	VRFPrefix = "substrate-babe-vrf"
+
+	// RandomnessLength is the length in bytes of the epoch randomness
+	RandomnessLength = 32
+)
+
+var (
+	ErrInvalidConstant       = errors.New("babe constant c must be in the range [0, 1)")
+	ErrInvalidAuthorityIndex = errors.New("authority index out of range")
+	ErrAuthorityWeightIsZero = errors.New("authority weight is zero")
+	ErrThresholdOutOfRange   = errors.New("primary threshold does not fit in 128 bits")
+	ErrTotalWeightOverflow   = errors.New("total authority weight overflows uint64")
+)
+
+// maxThreshold is 2^128, one more than the largest u128
+var maxThreshold = new(big.Int).Lsh(big.NewInt(1), 128)
+
+// Randomness is the randomness of a BABE epoch
+type Randomness [RandomnessLength]byte
+
+// Ratio is the BABE constant c, the probability of a slot being occupied, as a fraction
+type Ratio struct {
+	Numerator   uint64
+	Denominator uint64
+}
+
+// Authority is a BABE authority and its weight
+type Authority struct {
+	Key    *schnorrkel.PublicKey
+	Weight uint64
+}
+
+// SlotClaim is the VRF output and proof claiming a primary slot
+type SlotClaim struct {
+	Output *schnorrkel.VrfOutput
+	Proof  *schnorrkel.VrfProof
+}
+
+// MakeTranscript returns the VRF transcript for the given epoch randomness, slot and epoch
+// see: https://github.com/paritytech/substrate/blob/master/primitives/consensus/babe/src/lib.rs
+func MakeTranscript(randomness Randomness, slot, epoch uint64) *merlin.Transcript {
+	t := merlin.NewTranscript(EngineID)
+	appendUint64(t, []byte("slot number"), slot)
+	appendUint64(t, []byte("current epoch"), epoch)
+	t.AppendMessage([]byte("chain randomness"), randomness[:])
+	return t
+}
+
+func appendUint64(t *merlin.Transcript, label []byte, v uint64) {
+	b := [8]byte{}
+	binary.LittleEndian.PutUint64(b[:], v)
+	t.AppendMessage(label, b[:])
+}
+
+// CalculatePrimaryThreshold returns the threshold for the authority at the given index to
+// claim a primary slot, 2^128 * (1 - (1 - c)^theta) where theta is the authority's share of
+// the total weight. The probability is computed in floating point, with a pow that gives the
+// same bits as Substrate's f64::powf, and then scaled to 128 bits with exact rational
+// arithmetic, as in Substrate.
+// see: https://github.com/paritytech/substrate/blob/master/client/consensus/babe/src/authorship.rs
+func CalculatePrimaryThreshold(c Ratio, authorities []Authority, index int) (*big.Int, error) {
+	if c.Denominator == 0 || c.Numerator >= c.Denominator {
+		return nil, ErrInvalidConstant
+	}
+
+	if index < 0 || index >= len(authorities) {
+		return nil, ErrInvalidAuthorityIndex
+	}
+
+	total := uint64(0)
+	for _, a := range authorities {
+		if total+a.Weight < total {
+			return nil, ErrTotalWeightOverflow
+		}
+		total += a.Weight
+	}
+
+	if authorities[index].Weight == 0 {
+		return nil, ErrAuthorityWeightIsZero
+	}
+
+	cf := float64(c.Numerator) / float64(c.Denominator)
+	theta := float64(authorities[index].Weight) / float64(total)
+	p := new(big.Rat).SetFloat64(1 - pow(1-cf, theta))
+	if p == nil {
+		return nil, ErrThresholdOutOfRange
+	}
+
+	threshold := new(big.Int).Mul(maxThreshold, p.Num())
+	threshold.Quo(threshold, p.Denom())
+	if threshold.Cmp(maxThreshold) >= 0 {
+		return nil, ErrThresholdOutOfRange
+	}
+
+	return threshold, nil
+}
+
+// CheckPrimaryThreshold returns true if the VRF output is below the threshold, interpreting
+// 16 bytes made from the output with the VRFPrefix context as a little-endian u128
+func CheckPrimaryThreshold(inout *schnorrkel.VrfInOut, threshold *big.Int) (bool, error) {
+	b, err := inout.MakeBytes(16, []byte(VRFPrefix))
+	if err != nil {
+		return false, err
+	}
+
+	// big.Int takes big-endian bytes
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+
+	return new(big.Int).SetBytes(b).Cmp(threshold) < 0, nil
+}
+
+// ClaimPrimarySlot attempts to claim the primary slot with the keypair. If the VRF output
+// for the slot is not below the threshold, it returns a nil claim and no proof is created.
+func ClaimPrimarySlot(kp *schnorrkel.Keypair, randomness Randomness, slot, epoch uint64, threshold *big.Int) (*SlotClaim, error) {
+	if kp == nil {
+		return nil, errors.New("keypair provided is nil")
+	}
+
+	var checkErr error
+	inout, proof, err := kp.VrfSignAfterCheck(MakeTranscript(randomness, slot, epoch), func(inout *schnorrkel.VrfInOut) bool {
+		var ok bool
+		ok, checkErr = CheckPrimaryThreshold(inout, threshold)
+		return ok
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if checkErr != nil {
+		return nil, checkErr
+	}
+
+	if proof == nil {
+		return nil, nil
+	}
+
+	return &SlotClaim{
+		Output: inout.Output(),
+		Proof:  proof,
+	}, nil
+}
+
+// VerifyPrimaryClaim verifies that the VRF output and proof are valid for the public key and
+// slot, and that the output is below the threshold
+func VerifyPrimaryClaim(pub *schnorrkel.PublicKey, randomness Randomness, slot, epoch uint64, threshold *big.Int, claim *SlotClaim) (bool, error) {
+	if pub == nil {
+		return false, errors.New("public key provided is nil")
+	}
+
+	if claim == nil || claim.Output == nil || claim.Proof == nil {
+		return false, errors.New("claim provided is incomplete")
+	}
+
+	inout, ok, err := pub.VrfVerifyInOut(MakeTranscript(randomness, slot, epoch), claim.Output, claim.Proof)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	return CheckPrimaryThreshold(inout, threshold)
+}
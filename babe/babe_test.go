@@ -0,0 +1,182 @@
+package babe
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/ChainSafe/go-schnorrkel"
+	"github.com/stretchr/testify/require"
+)
+
+func newAuthority(t *testing.T, weight uint64) (*schnorrkel.Keypair, Authority) {
+	priv, pub, err := schnorrkel.GenerateKeypair()
+	require.NoError(t, err)
+	return schnorrkel.NewKeypair(pub, priv), Authority{Key: pub, Weight: weight}
+}
+
+func TestCalculatePrimaryThreshold(t *testing.T) {
+	_, a := newAuthority(t, 1)
+	_, b := newAuthority(t, 1)
+	_, c := newAuthority(t, 2)
+
+	// theta = 1: the threshold is exactly c * 2^128
+	threshold, err := CalculatePrimaryThreshold(Ratio{1, 4}, []Authority{a}, 0)
+	require.NoError(t, err)
+	require.Equal(t, new(big.Int).Lsh(big.NewInt(1), 126), threshold)
+
+	// theta = 1/4: 2^128 * (1 - 0.75^0.25)
+	threshold, err = CalculatePrimaryThreshold(Ratio{1, 4}, []Authority{a, b, c}, 0)
+	require.NoError(t, err)
+	expected, ok := new(big.Int).SetString("23613942797549581433038601094032261120", 10)
+	require.True(t, ok)
+	require.Equal(t, expected, threshold)
+
+	// a heavier authority has a higher threshold
+	heavier, err := CalculatePrimaryThreshold(Ratio{1, 4}, []Authority{a, b, c}, 2)
+	require.NoError(t, err)
+	require.Equal(t, 1, heavier.Cmp(threshold))
+
+	_, err = CalculatePrimaryThreshold(Ratio{1, 1}, []Authority{a}, 0)
+	require.ErrorIs(t, err, ErrInvalidConstant)
+
+	_, err = CalculatePrimaryThreshold(Ratio{1, 0}, []Authority{a}, 0)
+	require.ErrorIs(t, err, ErrInvalidConstant)
+
+	_, err = CalculatePrimaryThreshold(Ratio{1, 4}, []Authority{a}, 1)
+	require.ErrorIs(t, err, ErrInvalidAuthorityIndex)
+
+	_, err = CalculatePrimaryThreshold(Ratio{1, 4}, []Authority{a, {Key: b.Key, Weight: 0}}, 1)
+	require.ErrorIs(t, err, ErrAuthorityWeightIsZero)
+}
+
+func TestCalculatePrimaryThreshold_Substrate(t *testing.T) {
+	equal := func(n int) []uint64 {
+		weights := make([]uint64, n)
+		for i := range weights {
+			weights[i] = 1
+		}
+		return weights
+	}
+
+	// thresholds from Substrate's calculate_primary_threshold with Rust's f64::powf on x86-64 Linux
+	vectors := []struct {
+		c         Ratio
+		weights   []uint64
+		index     int
+		threshold string
+	}{
+		{Ratio{1, 4}, []uint64{1}, 0, "85070591730234615865843651857942052864"},
+		{Ratio{1, 4}, equal(3), 1, "31115318766088776340791719032032067584"},
+		{Ratio{1, 4}, []uint64{1, 1, 2}, 0, "23613942797549581433038601094032261120"},
+		{Ratio{1, 4}, []uint64{1, 1, 2}, 2, "45589192707508239153098207983084503040"},
+		{Ratio{1, 4}, equal(6), 0, "15930559588079594848632544399393292288"},
+		{Ratio{1, 4}, equal(297), 5, "329446939041455646821181862768017408"},
+		{Ratio{1, 4}, equal(1000), 0, "97879056834606603158816597907341312"},
+		{Ratio{1, 2}, []uint64{1, 2, 3, 4}, 3, "82396556504220324906576010269182394368"},
+		{Ratio{1, 2}, []uint64{1, 2, 3, 4}, 0, "22787692145469692291464676431244558336"},
+		{Ratio{3, 10}, []uint64{1000000, 1}, 0, "102084625117215447841404355678436327424"},
+		{Ratio{3, 10}, []uint64{1000000, 1}, 1, "121370051142928629010712622006272"},
+		{Ratio{1, 10}, []uint64{100, 100, 100, 100, 100, 100, 100}, 6, "5083408371442844864356067910965264384"},
+		{Ratio{5, 8}, []uint64{3, 5, 7, 11, 13}, 4, "94896318183482789730970061790479646720"},
+		{Ratio{1, 100}, []uint64{1 << 62, 1<<62 - 1, 5}, 0, "1705686765699425948230896411696168960"},
+		{Ratio{1, 100}, []uint64{1 << 62, 1<<62 - 1, 5}, 2, "0"},
+	}
+
+	for _, v := range vectors {
+		authorities := make([]Authority, len(v.weights))
+		for i, w := range v.weights {
+			authorities[i] = Authority{Weight: w}
+		}
+
+		threshold, err := CalculatePrimaryThreshold(v.c, authorities, v.index)
+		require.NoError(t, err)
+		require.Equal(t, v.threshold, threshold.String(), "c = %d/%d, index %d", v.c.Numerator, v.c.Denominator, v.index)
+	}
+}
+
+func TestCalculatePrimaryThreshold_WeightOverflow(t *testing.T) {
+	authorities := []Authority{{Weight: 1 << 63}, {Weight: 1 << 63}}
+	_, err := CalculatePrimaryThreshold(Ratio{1, 4}, authorities, 0)
+	require.ErrorIs(t, err, ErrTotalWeightOverflow)
+}
+
+func TestPow(t *testing.T) {
+	// inputs for which math.Pow is one ULP away from glibc's pow, which Rust's f64::powf calls
+	vectors := []struct {
+		x, y     float64
+		expected uint64
+	}{
+		{0.30091186058528707, 0.5152126285020654, 4603026709250063081},
+		{0.39998376285699544, 0.497868113342702, 4603882896064391761},
+		{0.559392449071014, 0.8154051709333606, 4603784105131938541},
+		{0.9579539135375136, 0.7972085409108028, 4606879192616708116},
+		{0.09838378898573259, 0.5203802857122278, 4599061226094613288},
+	}
+
+	for _, v := range vectors {
+		require.Equal(t, v.expected, math.Float64bits(pow(v.x, v.y)))
+	}
+
+	require.Equal(t, 1.0, pow(1, 0.5))
+	require.Equal(t, 0.0, pow(0, 0.5))
+}
+
+func TestClaimAndVerifyPrimarySlot(t *testing.T) {
+	kp, a := newAuthority(t, 1)
+	_, b := newAuthority(t, 1)
+	randomness := Randomness{1, 2, 3}
+	epoch := uint64(7)
+
+	threshold, err := CalculatePrimaryThreshold(Ratio{1, 2}, []Authority{a, b}, 0)
+	require.NoError(t, err)
+
+	claimed, unclaimed := 0, 0
+	for slot := uint64(0); slot < 64; slot++ {
+		claim, err := ClaimPrimarySlot(kp, randomness, slot, epoch, threshold)
+		require.NoError(t, err)
+
+		// the VRF output decides the claim independently of the proof
+		inout, err := kp.VrfCreateHash(MakeTranscript(randomness, slot, epoch))
+		require.NoError(t, err)
+		below, err := CheckPrimaryThreshold(inout, threshold)
+		require.NoError(t, err)
+		require.Equal(t, below, claim != nil)
+
+		if claim == nil {
+			unclaimed++
+			continue
+		}
+		claimed++
+
+		ok, err := VerifyPrimaryClaim(a.Key, randomness, slot, epoch, threshold, claim)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		// the claim is bound to the slot, the epoch, the randomness and the authority
+		ok, err = VerifyPrimaryClaim(a.Key, randomness, slot+1, epoch, threshold, claim)
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		ok, err = VerifyPrimaryClaim(a.Key, randomness, slot, epoch+1, threshold, claim)
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		ok, err = VerifyPrimaryClaim(a.Key, Randomness{}, slot, epoch, threshold, claim)
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		ok, err = VerifyPrimaryClaim(b.Key, randomness, slot, epoch, threshold, claim)
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		// a valid proof above the threshold is rejected
+		ok, err = VerifyPrimaryClaim(a.Key, randomness, slot, epoch, big.NewInt(0), claim)
+		require.NoError(t, err)
+		require.False(t, ok)
+	}
+
+	// with p = 1 - 0.5^0.5 ≈ 0.29 both outcomes are practically certain over 64 slots
+	require.NotZero(t, claimed)
+	require.NotZero(t, unclaimed)
+}
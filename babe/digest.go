@@ -0,0 +1,267 @@
+package babe
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	"github.com/ChainSafe/go-schnorrkel"
+	"golang.org/x/crypto/blake2b"
+)
+
+// SCALE enum indices of the PreDigest variants
+const (
+	primaryPreDigestIndex        = 1
+	secondaryPlainPreDigestIndex = 2
+	secondaryVRFPreDigestIndex   = 3
+)
+
+const (
+	authorityIndexLength = 4
+	slotLength           = 8
+)
+
+// AllowedSlots are the types of slots that authorities may produce blocks in
+type AllowedSlots byte
+
+const (
+	// PrimarySlots allows only primary slots
+	PrimarySlots AllowedSlots = iota
+	// PrimaryAndSecondaryPlainSlots allows primary and secondary plain slots
+	PrimaryAndSecondaryPlainSlots
+	// PrimaryAndSecondaryVRFSlots allows primary and secondary VRF slots
+	PrimaryAndSecondaryVRFSlots
+)
+
+var (
+	ErrInvalidPreDigest                 = errors.New("invalid BABE pre-digest encoding")
+	ErrSecondarySlotAssignmentsDisabled = errors.New("secondary slot assignments are disabled for the epoch")
+	ErrNoAuthorities                    = errors.New("authority set is empty")
+	ErrIncompletePreDigest              = errors.New("pre-digest VRF output or proof is nil")
+)
+
+// PreDigest is a BABE pre-runtime digest, one of PrimaryPreDigest, SecondaryPlainPreDigest
+// or SecondaryVRFPreDigest
+// see: https://github.com/paritytech/substrate/blob/master/primitives/consensus/babe/src/digests.rs
+type PreDigest interface {
+	// Encode returns the SCALE encoding of the pre-digest
+	Encode() ([]byte, error)
+
+	authorityIndex() uint32
+	slot() uint64
+}
+
+// PrimaryPreDigest is the pre-digest of a block authored in a primary slot
+type PrimaryPreDigest struct {
+	AuthorityIndex uint32
+	Slot           uint64
+	VrfOutput      *schnorrkel.VrfOutput
+	VrfProof       *schnorrkel.VrfProof
+}
+
+// SecondaryPlainPreDigest is the pre-digest of a block authored in a secondary slot
+// without a VRF
+type SecondaryPlainPreDigest struct {
+	AuthorityIndex uint32
+	Slot           uint64
+}
+
+// SecondaryVRFPreDigest is the pre-digest of a block authored in a secondary slot with a VRF
+type SecondaryVRFPreDigest struct {
+	AuthorityIndex uint32
+	Slot           uint64
+	VrfOutput      *schnorrkel.VrfOutput
+	VrfProof       *schnorrkel.VrfProof
+}
+
+// Epoch holds the parameters of a BABE epoch needed to verify pre-digests
+type Epoch struct {
+	Index        uint64
+	Randomness   Randomness
+	Authorities  []Authority
+	C            Ratio
+	AllowedSlots AllowedSlots
+}
+
+// Encode returns the SCALE encoding of the pre-digest
+func (d *PrimaryPreDigest) Encode() ([]byte, error) {
+	return encodePreDigest(primaryPreDigestIndex, d.AuthorityIndex, d.Slot, d.VrfOutput, d.VrfProof)
+}
+
+func (d *PrimaryPreDigest) authorityIndex() uint32 { return d.AuthorityIndex }
+func (d *PrimaryPreDigest) slot() uint64           { return d.Slot }
+
+// Encode returns the SCALE encoding of the pre-digest
+func (d *SecondaryPlainPreDigest) Encode() ([]byte, error) {
+	return encodePreDigest(secondaryPlainPreDigestIndex, d.AuthorityIndex, d.Slot, nil, nil)
+}
+
+func (d *SecondaryPlainPreDigest) authorityIndex() uint32 { return d.AuthorityIndex }
+func (d *SecondaryPlainPreDigest) slot() uint64           { return d.Slot }
+
+// Encode returns the SCALE encoding of the pre-digest
+func (d *SecondaryVRFPreDigest) Encode() ([]byte, error) {
+	return encodePreDigest(secondaryVRFPreDigestIndex, d.AuthorityIndex, d.Slot, d.VrfOutput, d.VrfProof)
+}
+
+func (d *SecondaryVRFPreDigest) authorityIndex() uint32 { return d.AuthorityIndex }
+func (d *SecondaryVRFPreDigest) slot() uint64           { return d.Slot }
+
+func encodePreDigest(index byte, authorityIndex uint32, slot uint64, out *schnorrkel.VrfOutput, proof *schnorrkel.VrfProof) ([]byte, error) {
+	if index != secondaryPlainPreDigestIndex && (out == nil || proof == nil) {
+		return nil, ErrIncompletePreDigest
+	}
+
+	enc := make([]byte, 0, 1+authorityIndexLength+slotLength+schnorrkel.VrfSignatureSize)
+	enc = append(enc, index)
+	enc = binary.LittleEndian.AppendUint32(enc, authorityIndex)
+	enc = binary.LittleEndian.AppendUint64(enc, slot)
+	if index == secondaryPlainPreDigestIndex {
+		return enc, nil
+	}
+
+	sigenc := schnorrkel.NewVrfSignature(out, proof).Encode()
+	return append(enc, sigenc[:]...), nil
+}
+
+// DecodePreDigest decodes a SCALE encoded PreDigest
+func DecodePreDigest(in []byte) (PreDigest, error) {
+	if len(in) < 1+authorityIndexLength+slotLength {
+		return nil, ErrInvalidPreDigest
+	}
+
+	authorityIndex := binary.LittleEndian.Uint32(in[1:])
+	slot := binary.LittleEndian.Uint64(in[1+authorityIndexLength:])
+	rest := in[1+authorityIndexLength+slotLength:]
+
+	switch in[0] {
+	case primaryPreDigestIndex, secondaryVRFPreDigestIndex:
+		out, proof, err := decodeVrfSignature(rest)
+		if err != nil {
+			return nil, err
+		}
+
+		if in[0] == primaryPreDigestIndex {
+			return &PrimaryPreDigest{
+				AuthorityIndex: authorityIndex,
+				Slot:           slot,
+				VrfOutput:      out,
+				VrfProof:       proof,
+			}, nil
+		}
+
+		return &SecondaryVRFPreDigest{
+			AuthorityIndex: authorityIndex,
+			Slot:           slot,
+			VrfOutput:      out,
+			VrfProof:       proof,
+		}, nil
+	case secondaryPlainPreDigestIndex:
+		if len(rest) != 0 {
+			return nil, ErrInvalidPreDigest
+		}
+
+		return &SecondaryPlainPreDigest{
+			AuthorityIndex: authorityIndex,
+			Slot:           slot,
+		}, nil
+	default:
+		return nil, ErrInvalidPreDigest
+	}
+}
+
+func decodeVrfSignature(in []byte) (*schnorrkel.VrfOutput, *schnorrkel.VrfProof, error) {
+	if len(in) != schnorrkel.VrfSignatureSize {
+		return nil, nil, ErrInvalidPreDigest
+	}
+
+	enc := [schnorrkel.VrfSignatureSize]byte{}
+	copy(enc[:], in)
+	sig := new(schnorrkel.VrfSignature)
+	err := sig.Decode(enc)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return sig.Output(), sig.Proof(), nil
+}
+
+// SecondarySlotAuthor returns the index of the authority expected to author the secondary
+// slot: blake2b-256(randomness || slot) interpreted as a big-endian 256-bit integer, modulo
+// the number of authorities
+// see: https://github.com/paritytech/substrate/blob/master/client/consensus/babe/src/authorship.rs
+func SecondarySlotAuthor(slot uint64, authorities []Authority, randomness Randomness) (int, error) {
+	if len(authorities) == 0 {
+		return 0, ErrNoAuthorities
+	}
+
+	enc := binary.LittleEndian.AppendUint64(append([]byte{}, randomness[:]...), slot)
+	h := blake2b.Sum256(enc)
+	idx := new(big.Int).SetBytes(h[:])
+	idx.Mod(idx, big.NewInt(int64(len(authorities))))
+	return int(idx.Int64()), nil
+}
+
+// VerifyPreDigest verifies the pre-digest against the epoch. For primary slots it checks the
+// VRF proof and the authority's threshold, for secondary slots it checks that the author is
+// the expected secondary slot author and, for secondary VRF slots, the VRF proof.
+// It returns an error if the pre-digest is malformed or not allowed in the epoch.
+// see: https://github.com/paritytech/substrate/blob/master/client/consensus/babe/src/verification.rs
+func (e *Epoch) VerifyPreDigest(d PreDigest) (bool, error) {
+	if d == nil {
+		return false, errors.New("pre-digest provided is nil")
+	}
+
+	if int64(d.authorityIndex()) >= int64(len(e.Authorities)) {
+		return false, ErrInvalidAuthorityIndex
+	}
+
+	author := e.Authorities[d.authorityIndex()]
+
+	switch d := d.(type) {
+	case *PrimaryPreDigest:
+		if d.VrfOutput == nil || d.VrfProof == nil {
+			return false, ErrIncompletePreDigest
+		}
+
+		threshold, err := CalculatePrimaryThreshold(e.C, e.Authorities, int(d.AuthorityIndex))
+		if err != nil {
+			return false, err
+		}
+
+		claim := &SlotClaim{Output: d.VrfOutput, Proof: d.VrfProof}
+		return VerifyPrimaryClaim(author.Key, e.Randomness, d.Slot, e.Index, threshold, claim)
+	case *SecondaryPlainPreDigest:
+		if e.AllowedSlots != PrimaryAndSecondaryPlainSlots {
+			return false, ErrSecondarySlotAssignmentsDisabled
+		}
+
+		return e.isSecondarySlotAuthor(d.Slot, d.AuthorityIndex)
+	case *SecondaryVRFPreDigest:
+		if e.AllowedSlots != PrimaryAndSecondaryVRFSlots {
+			return false, ErrSecondarySlotAssignmentsDisabled
+		}
+
+		ok, err := e.isSecondarySlotAuthor(d.Slot, d.AuthorityIndex)
+		if err != nil || !ok {
+			return false, err
+		}
+
+		if d.VrfOutput == nil || d.VrfProof == nil {
+			return false, ErrIncompletePreDigest
+		}
+
+		return author.Key.VrfVerify(MakeTranscript(e.Randomness, d.Slot, e.Index), d.VrfOutput, d.VrfProof)
+	default:
+		return false, ErrInvalidPreDigest
+	}
+}
+
+func (e *Epoch) isSecondarySlotAuthor(slot uint64, authorityIndex uint32) (bool, error) {
+	expected, err := SecondarySlotAuthor(slot, e.Authorities, e.Randomness)
+	if err != nil {
+		return false, err
+	}
+
+	return expected == int(authorityIndex), nil
+}
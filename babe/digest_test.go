@@ -0,0 +1,212 @@
+package babe
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/ChainSafe/go-schnorrkel"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/blake2b"
+)
+
+func TestPreDigest_EncodeAndDecode(t *testing.T) {
+	kp, _ := newAuthority(t, 1)
+	inout, proof, err := kp.VrfSign(MakeTranscript(Randomness{}, 1, 0))
+	require.NoError(t, err)
+
+	digests := []PreDigest{
+		&PrimaryPreDigest{AuthorityIndex: 3, Slot: 1, VrfOutput: inout.Output(), VrfProof: proof},
+		&SecondaryPlainPreDigest{AuthorityIndex: 4, Slot: 2},
+		&SecondaryVRFPreDigest{AuthorityIndex: 5, Slot: 3, VrfOutput: inout.Output(), VrfProof: proof},
+	}
+
+	for i, d := range digests {
+		enc, err := d.Encode()
+		require.NoError(t, err)
+		require.Equal(t, byte(i+1), enc[0])
+
+		dec, err := DecodePreDigest(enc)
+		require.NoError(t, err)
+		require.IsType(t, d, dec)
+		decenc, err := dec.Encode()
+		require.NoError(t, err)
+		require.Equal(t, enc, decenc)
+	}
+
+	// index || authority index || slot || pre-output || proof
+	enc, err := digests[0].Encode()
+	require.NoError(t, err)
+	require.Len(t, enc, 109)
+	require.Equal(t, uint32(3), binary.LittleEndian.Uint32(enc[1:5]))
+	require.Equal(t, uint64(1), binary.LittleEndian.Uint64(enc[5:13]))
+	outenc := inout.Output().Encode()
+	require.Equal(t, outenc[:], enc[13:45])
+	proofenc := proof.Encode()
+	require.Equal(t, proofenc[:], enc[45:])
+
+	enc, err = digests[1].Encode()
+	require.NoError(t, err)
+	require.Len(t, enc, 13)
+}
+
+func TestPreDigest_EncodeIncomplete(t *testing.T) {
+	kp, _ := newAuthority(t, 1)
+	inout, proof, err := kp.VrfSign(MakeTranscript(Randomness{}, 1, 0))
+	require.NoError(t, err)
+
+	for _, d := range []PreDigest{
+		&PrimaryPreDigest{},
+		&PrimaryPreDigest{VrfOutput: inout.Output()},
+		&SecondaryVRFPreDigest{VrfProof: proof},
+	} {
+		_, err := d.Encode()
+		require.ErrorIs(t, err, ErrIncompletePreDigest)
+	}
+}
+
+func TestDecodePreDigest_Invalid(t *testing.T) {
+	valid, err := (&SecondaryPlainPreDigest{AuthorityIndex: 1, Slot: 2}).Encode()
+	require.NoError(t, err)
+
+	for _, in := range [][]byte{
+		nil,
+		valid[:12],
+		append(append([]byte{}, valid...), 0),
+		append([]byte{4}, valid[1:]...),
+		append([]byte{1}, valid[1:]...),
+	} {
+		_, err = DecodePreDigest(in)
+		require.ErrorIs(t, err, ErrInvalidPreDigest)
+	}
+}
+
+func TestSecondarySlotAuthor(t *testing.T) {
+	_, a := newAuthority(t, 1)
+	_, b := newAuthority(t, 1)
+	_, c := newAuthority(t, 1)
+	authorities := []Authority{a, b, c}
+	randomness := Randomness{9, 8, 7}
+
+	counts := make([]int, len(authorities))
+	for slot := uint64(0); slot < 30; slot++ {
+		idx, err := SecondarySlotAuthor(slot, authorities, randomness)
+		require.NoError(t, err)
+
+		enc := append(append([]byte{}, randomness[:]...), make([]byte, 8)...)
+		binary.LittleEndian.PutUint64(enc[32:], slot)
+		h := blake2b.Sum256(enc)
+
+		// h mod 3, with h as a big-endian integer, is the sum of its bytes mod 3 since 256 = 1 mod 3
+		sum := 0
+		for _, v := range h {
+			sum += int(v)
+		}
+		require.Equal(t, sum%3, idx)
+		counts[idx]++
+	}
+
+	for _, n := range counts {
+		require.NotZero(t, n)
+	}
+
+	_, err := SecondarySlotAuthor(0, nil, randomness)
+	require.ErrorIs(t, err, ErrNoAuthorities)
+}
+
+func newTestEpoch(t *testing.T, allowed AllowedSlots) (*Epoch, []*schnorrkel.Keypair) {
+	keypairs := make([]*schnorrkel.Keypair, 3)
+	authorities := make([]Authority, 3)
+	for i := range authorities {
+		keypairs[i], authorities[i] = newAuthority(t, 1)
+	}
+
+	return &Epoch{
+		Index:        4,
+		Randomness:   Randomness{1, 1, 2, 3, 5, 8},
+		Authorities:  authorities,
+		C:            Ratio{1, 2},
+		AllowedSlots: allowed,
+	}, keypairs
+}
+
+func TestEpoch_VerifyPreDigest_Primary(t *testing.T) {
+	epoch, keypairs := newTestEpoch(t, PrimarySlots)
+	threshold, err := CalculatePrimaryThreshold(epoch.C, epoch.Authorities, 1)
+	require.NoError(t, err)
+
+	var claim *SlotClaim
+	slot := uint64(0)
+	for ; claim == nil; slot++ {
+		claim, err = ClaimPrimarySlot(keypairs[1], epoch.Randomness, slot, epoch.Index, threshold)
+		require.NoError(t, err)
+	}
+	slot--
+
+	d := &PrimaryPreDigest{AuthorityIndex: 1, Slot: slot, VrfOutput: claim.Output, VrfProof: claim.Proof}
+	enc, err := d.Encode()
+	require.NoError(t, err)
+	dec, err := DecodePreDigest(enc)
+	require.NoError(t, err)
+
+	ok, err := epoch.VerifyPreDigest(dec)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	d.AuthorityIndex = 2
+	ok, err = epoch.VerifyPreDigest(d)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	d.AuthorityIndex = 3
+	_, err = epoch.VerifyPreDigest(d)
+	require.ErrorIs(t, err, ErrInvalidAuthorityIndex)
+}
+
+func TestEpoch_VerifyPreDigest_Secondary(t *testing.T) {
+	plain, _ := newTestEpoch(t, PrimaryAndSecondaryPlainSlots)
+	vrf, keypairs := newTestEpoch(t, PrimaryAndSecondaryVRFSlots)
+	slot := uint64(11)
+
+	expected, err := SecondarySlotAuthor(slot, plain.Authorities, plain.Randomness)
+	require.NoError(t, err)
+	other := uint32((expected + 1) % len(plain.Authorities))
+
+	ok, err := plain.VerifyPreDigest(&SecondaryPlainPreDigest{AuthorityIndex: uint32(expected), Slot: slot})
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = plain.VerifyPreDigest(&SecondaryPlainPreDigest{AuthorityIndex: other, Slot: slot})
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	_, err = vrf.VerifyPreDigest(&SecondaryPlainPreDigest{AuthorityIndex: uint32(expected), Slot: slot})
+	require.ErrorIs(t, err, ErrSecondarySlotAssignmentsDisabled)
+
+	expected, err = SecondarySlotAuthor(slot, vrf.Authorities, vrf.Randomness)
+	require.NoError(t, err)
+
+	inout, proof, err := keypairs[expected].VrfSign(MakeTranscript(vrf.Randomness, slot, vrf.Index))
+	require.NoError(t, err)
+
+	d := &SecondaryVRFPreDigest{AuthorityIndex: uint32(expected), Slot: slot, VrfOutput: inout.Output(), VrfProof: proof}
+	ok, err = vrf.VerifyPreDigest(d)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	d.Slot++
+	ok, err = vrf.VerifyPreDigest(d)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	primaryOnly, _ := newTestEpoch(t, PrimarySlots)
+	primaryOnly.Authorities = vrf.Authorities
+	_, err = primaryOnly.VerifyPreDigest(d)
+	require.ErrorIs(t, err, ErrSecondarySlotAssignmentsDisabled)
+
+	d.Slot--
+	d.VrfProof = nil
+	_, err = vrf.VerifyPreDigest(d)
+	require.ErrorIs(t, err, ErrIncompletePreDigest)
+	_, err = vrf.VerifyPreDigest(&PrimaryPreDigest{AuthorityIndex: uint32(expected), Slot: slot})
+	require.ErrorIs(t, err, ErrIncompletePreDigest)
+}
@@ -0,0 +1,108 @@
+package babe
+
+import "math"
+
+// Constants of the pow of ARM's optimized-routines (__pow_log_data and __exp_data in glibc)
+const (
+	powOff = 0x3fe6955500000000
+
+	ln2hi = 0x1.62e42fefa3800p-1
+	ln2lo = 0x1.ef35793c76730p-45
+
+	// log1p polynomial, scaled to match the evaluation in powLog
+	logA0 = -0x1p-1
+	logA1 = -0x1.5555555555560p-1
+	logA2 = 0x1.0000000000006p-1
+	logA3 = 0x1.999999959554ep-1
+	logA4 = -0x1.555555529a47ap-1
+	logA5 = -0x1.2495b9b4845e9p+0
+	logA6 = 0x1.0002b8b263fc3p+0
+
+	invLn2N   = 0x1.71547652b82fep+7
+	negLn2hiN = -0x1.62e42fefa0000p-8
+	negLn2loN = -0x1.cf79abc9e3b3ap-47
+	expShift  = 0x1.8p52
+
+	// exp polynomial
+	expC2 = 0x1.ffffffffffdbdp-2
+	expC3 = 0x1.555555555543cp-3
+	expC4 = 0x1.55555cf172b91p-5
+	expC5 = 0x1.1111167a4d017p-7
+)
+
+// pow returns x**y for 0 <= x <= 1 and 0 < y <= 1, the domain of the BABE threshold.
+//
+// Substrate computes the threshold with Rust's f64::powf, which calls the platform libm, and
+// math.Pow differs from it in the last bit for some inputs. This is a port of the pow of ARM's
+// optimized-routines, which glibc 2.28+ and musl use, with the fused multiply-adds of glibc's
+// x86-64 FMA variant, so it gives the same bits as Substrate on x86-64 Linux with FMA. Other
+// libms, and glibc's non-FMA variant, may still differ in the last bit for rare inputs.
+func pow(x, y float64) float64 {
+	if x == 0 {
+		return 0
+	}
+
+	hi, lo := powLog(math.Float64bits(x))
+	ehi := float64(y * hi)
+	elo := math.FMA(y, lo, math.FMA(y, hi, -ehi))
+	return powExp(ehi, elo)
+}
+
+// powLog returns log(x) as hi + lo for the bits ix of a normal x > 0
+func powLog(ix uint64) (float64, float64) {
+	// x = 2^k z, where z is in [0x1.6955p-1, 0x1.6955p0) and is in subinterval i
+	tmp := ix - powOff
+	i := (tmp >> (52 - 7)) % 128
+	k := int64(tmp) >> 52
+	z := math.Float64frombits(ix - tmp&(0xfff<<52))
+	kd := float64(k)
+
+	// log(x) = k ln(2) + log(c) + log1p(z/c - 1)
+	invc, logc, logctail := powLogTab[i].invc, powLogTab[i].logc, powLogTab[i].logctail
+	r := math.FMA(z, invc, -1)
+
+	t1 := math.FMA(kd, ln2hi, logc)
+	t2 := t1 + r
+	lo1 := math.FMA(kd, ln2lo, logctail)
+	lo2 := t1 - t2 + r
+
+	ar := float64(logA0 * r)
+	ar2 := float64(r * ar)
+	ar3 := float64(r * ar2)
+	hi := t2 + ar2
+	lo3 := math.FMA(ar, r, -ar2)
+	lo4 := t2 - hi + ar2
+
+	// log1p(r) - r - A0 r^2
+	p := math.FMA(ar2, math.FMA(r, logA6, logA5), math.FMA(r, logA4, logA3))
+	p = float64(ar3 * math.FMA(ar2, p, math.FMA(r, logA2, logA1)))
+
+	lo := lo1 + lo2 + lo3 + lo4 + p
+	y := hi + lo
+	return y, hi - y + lo
+}
+
+// powExp returns exp(x + xtail) for |x| < 512
+func powExp(x, xtail float64) float64 {
+	// |x| < 2^-54
+	if uint32(math.Float64bits(x)>>52)&0x7ff < 0x3c9 {
+		return 1 + x
+	}
+
+	// exp(x) = 2^(k/128) exp(r), where x = k ln(2)/128 + r and |r| <= ln(2)/256
+	kd := math.FMA(invLn2N, x, expShift)
+	ki := math.Float64bits(kd)
+	kd -= expShift
+	r := math.FMA(kd, negLn2loN, math.FMA(kd, negLn2hiN, x))
+	r += xtail
+
+	// 2^(k/128) = scale (1 + tail)
+	idx := 2 * (ki % 128)
+	tail := math.Float64frombits(powExpTab[idx])
+	scale := math.Float64frombits(powExpTab[idx+1] + ki<<(52-7))
+
+	r2 := float64(r * r)
+	tmp := math.FMA(r2, math.FMA(r, expC3, expC2), tail+r)
+	tmp = math.FMA(float64(r2*r2), math.FMA(r, expC5, expC4), tmp)
+	return math.FMA(scale, tmp, scale)
+}
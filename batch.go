@@ -1,7 +1,10 @@
 package schnorrkel
 
 import (
+	"context"
 	"errors"
+	"runtime"
+	"sync"
 
 	"github.com/gtank/merlin"
 	r255 "github.com/gtank/ristretto255"
@@ -17,88 +20,59 @@ func VerifyBatch(transcripts []*merlin.Transcript, signatures []*Signature, pubk
 		return true, nil
 	}
 
-	var err error
-	zero := r255.NewElement().Zero()
-	zs := make([]*r255.Scalar, len(transcripts))
-	for i := range zs {
-		zs[i], err = NewRandomScalar()
-		if err != nil {
+	v := NewBatchVerifier()
+	for i, t := range transcripts {
+		if err := checkBatchItem(t, signatures[i], pubkeys[i]); err != nil {
 			return false, err
 		}
-	}
 
-	// compute H(R_i || P_i || m_i)
-	hs := make([]*r255.Scalar, len(transcripts))
-	s := make([]r255.Scalar, len(transcripts))
-	for i, t := range transcripts {
-		if t == nil {
-			return false, errors.New("transcript provided was nil")
+		if err := v.Add(t, signatures[i], pubkeys[i]); err != nil {
+			return false, err
 		}
-
-		t.AppendMessage([]byte("proto-name"), []byte("Schnorr-sig"))
-		pubc := pubkeys[i].Encode()
-		t.AppendMessage([]byte("sign:pk"), pubc[:])
-		t.AppendMessage([]byte("sign:R"), signatures[i].r.Encode([]byte{}))
-
-		h := t.ExtractBytes([]byte("sign:c"), 64)
-		s[i] = *r255.NewScalar()
-		hs[i] = &s[i]
-		hs[i].FromUniformBytes(h)
 	}
 
-	// compute ∑ z_i P_i H(R_i || P_i || m_i)
-	ps := make([]*r255.Element, len(pubkeys))
-	for i, p := range pubkeys {
-		if p == nil {
-			return false, errors.New("public key provided was nil")
-		}
+	return v.Verify(), nil
+}
 
-		ps[i] = r255.NewElement().ScalarMult(zs[i], p.key)
+// checkBatchItem returns the error VerifyBatch has always returned for a nil input.
+func checkBatchItem(t *merlin.Transcript, sig *Signature, pubkey *PublicKey) error {
+	if t == nil {
+		return errors.New("transcript provided was nil")
 	}
 
-	phs := r255.NewElement().VarTimeMultiScalarMult(hs, ps)
-
-	// compute ∑ z_i s_i and ∑ z_i R_i
-	ss := r255.NewScalar()
-	rs := r255.NewElement()
-	for i, s := range signatures {
-		if s == nil {
-			return false, errors.New("signature provided was nil")
-		}
-
-		zsi := r255.NewScalar().Multiply(s.s, zs[i])
-		ss = r255.NewScalar().Add(ss, zsi)
-		zri := r255.NewElement().ScalarMult(zs[i], s.r)
-		rs = r255.NewElement().Add(rs, zri)
+	if pubkey == nil {
+		return errors.New("public key provided was nil")
 	}
 
-	// ∑ z_i P_i H(R_i || P_i || m_i) + ∑ R_i
-	z := r255.NewElement().Add(phs, rs)
-
-	// B ∑ z_i  s_i
-	sb := r255.NewElement().ScalarBaseMult(ss)
-
-	// check  -B ∑ z_i s_i + ∑ z_i P_i H(R_i || P_i || m_i) + ∑ z_i R_i = 0
-	sb_neg := r255.NewElement().Negate(sb)
-	res := r255.NewElement().Add(sb_neg, z)
+	if sig == nil {
+		return errors.New("signature provided was nil")
+	}
 
-	return res.Equal(zero) == 1, nil
+	return nil
 }
 
+// BatchVerifier accumulates signatures to be checked with a single batch equation:
+//
+//	-(∑ z_i s_i) B + ∑ z_i R_i + ∑_P (∑_{P_i = P} z_i H(R_i || P_i || m_i)) P = 0
+//
+// which is evaluated as one multiscalar multiplication over the base point, every R_i
+// and every distinct public key P.
+//
+// A BatchVerifier is safe for concurrent use by multiple goroutines.
 type BatchVerifier struct {
-	hs      []*r255.Scalar  // transcript scalar
-	ss      *r255.Scalar    // sum of signature.S: ∑ z_i s_i
-	rs      *r255.Element   // sum of signature.R: ∑ z_i R_i
-	pubkeys []*r255.Element // z_i P_i
+	mu      sync.Mutex
+	ss      *r255.Scalar                // sum of signature.S: ∑ z_i s_i
+	zs      []*r255.Scalar              // random weights z_i
+	rs      []*r255.Element             // signature.R: R_i
+	hs      []*r255.Scalar              // per public key: ∑ z_i H(R_i || P_i || m_i)
+	pubkeys []*r255.Element             // distinct public keys P
+	index   map[[PublicKeySize]byte]int // position of each public key in pubkeys
 }
 
 func NewBatchVerifier() *BatchVerifier {
-	return &BatchVerifier{
-		hs:      []*r255.Scalar{},
-		ss:      r255.NewScalar(),
-		rs:      r255.NewElement(),
-		pubkeys: []*r255.Element{},
-	}
+	v := &BatchVerifier{}
+	v.reset()
+	return v
 }
 
 func (v *BatchVerifier) Add(t *merlin.Transcript, sig *Signature, pubkey *PublicKey) error {
@@ -114,46 +88,213 @@ func (v *BatchVerifier) Add(t *merlin.Transcript, sig *Signature, pubkey *Public
 		return errors.New("provided public key is nil")
 	}
 
+	pubc := [PublicKeySize]byte{}
+	copy(pubc[:], pubkey.key.Encode([]byte{}))
+	return v.addWithChallenge(sig, pubkey, pubc, signatureChallenge(t, sig, pubc))
+}
+
+// addWithChallenge adds a signature whose challenge scalar H(R || P || m) has already
+// been computed.
+func (v *BatchVerifier) addWithChallenge(sig *Signature, pubkey *PublicKey, pubc [PublicKeySize]byte, k *r255.Scalar) error {
 	z, err := NewRandomScalar()
 	if err != nil {
 		return err
 	}
 
-	t.AppendMessage([]byte("proto-name"), []byte("Schnorr-sig"))
-	pubc := pubkey.Encode()
-	t.AppendMessage([]byte("sign:pk"), pubc[:])
-	t.AppendMessage([]byte("sign:R"), sig.r.Encode([]byte{}))
+	h := r255.NewScalar().Multiply(k, z)
+	zs := r255.NewScalar().Multiply(z, sig.s)
 
-	h := t.ExtractBytes([]byte("sign:c"), 64)
-	s := r255.NewScalar()
-	s.FromUniformBytes(h)
-	v.hs = append(v.hs, s)
+	v.mu.Lock()
+	defer v.mu.Unlock()
 
-	zs := r255.NewScalar().Multiply(z, sig.s)
 	v.ss.Add(v.ss, zs)
-	zr := r255.NewElement().ScalarMult(z, sig.r)
-	v.rs.Add(v.rs, zr)
-
-	p := r255.NewElement().ScalarMult(z, pubkey.key)
-	v.pubkeys = append(v.pubkeys, p)
+	v.zs = append(v.zs, z)
+	v.rs = append(v.rs, sig.r)
+	v.addPublicKeyTerm(pubc, pubkey.key, h)
 	return nil
 }
 
+// addPublicKeyTerm adds h to the scalar of the given public key. Signatures by the same
+// public key share one term of the multiscalar multiplication.
+func (v *BatchVerifier) addPublicKeyTerm(pubc [PublicKeySize]byte, pubkey *r255.Element, h *r255.Scalar) {
+	if i, ok := v.index[pubc]; ok {
+		v.hs[i].Add(v.hs[i], h)
+		return
+	}
+
+	v.index[pubc] = len(v.pubkeys)
+	v.hs = append(v.hs, r255.NewScalar().Add(r255.NewScalar(), h))
+	v.pubkeys = append(v.pubkeys, pubkey)
+}
+
+// Verify returns true if every signature added to the verifier is valid.
 func (v *BatchVerifier) Verify() bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
 	zero := r255.NewElement().Zero()
+	return v.sum().Equal(zero) == 1
+}
+
+// Len returns the number of signatures added to the verifier.
+func (v *BatchVerifier) Len() int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return len(v.zs)
+}
+
+// Reset removes all signatures from the verifier so that it can be reused.
+func (v *BatchVerifier) Reset() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.reset()
+}
+
+func (v *BatchVerifier) reset() {
+	v.ss = r255.NewScalar()
+	v.zs = []*r255.Scalar{}
+	v.rs = []*r255.Element{}
+	v.hs = []*r255.Scalar{}
+	v.pubkeys = []*r255.Element{}
+	v.index = make(map[[PublicKeySize]byte]int)
+}
+
+// Merge adds all signatures from other into the verifier. other is left unchanged.
+func (v *BatchVerifier) Merge(other *BatchVerifier) error {
+	if other == nil {
+		return errors.New("provided batch verifier is nil")
+	}
+
+	if other == v {
+		return errors.New("cannot merge a batch verifier into itself")
+	}
+
+	// copy other's state first so that the two locks are never held at the same time
+	other.mu.Lock()
+	ss := r255.NewScalar().Add(r255.NewScalar(), other.ss)
+	zs := append([]*r255.Scalar{}, other.zs...)
+	rs := append([]*r255.Element{}, other.rs...)
+	hs := make([]*r255.Scalar, len(other.hs))
+	for i, h := range other.hs {
+		hs[i] = r255.NewScalar().Add(r255.NewScalar(), h)
+	}
+	pubkeys := append([]*r255.Element{}, other.pubkeys...)
+	pubcs := make([][PublicKeySize]byte, len(other.pubkeys))
+	for pubc, i := range other.index {
+		pubcs[i] = pubc
+	}
+	other.mu.Unlock()
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.ss.Add(v.ss, ss)
+	v.zs = append(v.zs, zs...)
+	v.rs = append(v.rs, rs...)
+	for i, p := range pubkeys {
+		v.addPublicKeyTerm(pubcs[i], p, hs[i])
+	}
+	return nil
+}
+
+// sum evaluates the left-hand side of the batch equation.
+// The caller must hold v.mu.
+func (v *BatchVerifier) sum() *r255.Element {
+	scalars := make([]*r255.Scalar, 0, 1+len(v.zs)+len(v.hs))
+	points := make([]*r255.Element, 0, 1+len(v.rs)+len(v.pubkeys))
+
+	// -B ∑ z_i s_i
+	scalars = append(scalars, r255.NewScalar().Negate(v.ss))
+	points = append(points, r255.NewElement().Base())
+
+	// ∑ z_i R_i
+	scalars = append(scalars, v.zs...)
+	points = append(points, v.rs...)
 
-	// compute ∑ z_i P_i H(R_i || P_i || m_i)
-	phs := r255.NewElement().VarTimeMultiScalarMult(v.hs, v.pubkeys)
+	// ∑ z_i H(R_i || P_i || m_i) P_i
+	scalars = append(scalars, v.hs...)
+	points = append(points, v.pubkeys...)
 
-	// ∑ z_i P_i H(R_i || P_i || m_i) + ∑ z_i R_i
-	z := r255.NewElement().Add(phs, v.rs)
+	return r255.NewElement().VarTimeMultiScalarMult(scalars, points)
+}
 
-	// B ∑ z_i s_i
-	sb := r255.NewElement().ScalarBaseMult(v.ss)
+// VerifyBatchParallel batch verifies the given signatures, splitting the work across
+// GOMAXPROCS goroutines. Each worker evaluates the batch equation for its shard of the
+// batch, and the partial sums are combined for the final check.
+// If the context is cancelled before verification completes, ctx.Err() is returned.
+func VerifyBatchParallel(ctx context.Context, transcripts []*merlin.Transcript, signatures []*Signature, pubkeys []*PublicKey) (bool, error) {
+	if len(transcripts) != len(signatures) || len(signatures) != len(pubkeys) || len(pubkeys) != len(transcripts) {
+		return false, errors.New("the number of transcripts, signatures, and public keys must be equal")
+	}
 
-	// check  -B ∑ z_i s_i + ∑ z_i P_i H(R_i || P_i || m_i) + ∑ z_i R_i = 0
-	sb_neg := r255.NewElement().Negate(sb)
-	res := r255.NewElement().Add(sb_neg, z)
+	if len(transcripts) == 0 {
+		return true, nil
+	}
+
+	workers := runtime.GOMAXPROCS(0)
+	if workers > len(transcripts) {
+		workers = len(transcripts)
+	}
+
+	type partial struct {
+		sum *r255.Element
+		err error
+	}
+
+	chunk := (len(transcripts) + workers - 1) / workers
+	workers = (len(transcripts) + chunk - 1) / chunk
+	partials := make([]partial, workers)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		start := w * chunk
+		end := start + chunk
+		if end > len(transcripts) {
+			end = len(transcripts)
+		}
+
+		wg.Add(1)
+		go func(w, start, end int) {
+			defer wg.Done()
+			sum, err := verifyBatchPartial(ctx, transcripts[start:end], signatures[start:end], pubkeys[start:end])
+			partials[w] = partial{sum: sum, err: err}
+		}(w, start, end)
+	}
+	wg.Wait()
+
+	// the batch equation holds iff the partial sums add up to the identity
+	res := r255.NewElement().Zero()
+	for _, p := range partials {
+		if p.err != nil {
+			return false, p.err
+		}
+
+		res.Add(res, p.sum)
+	}
+
+	return res.Equal(r255.NewElement().Zero()) == 1, nil
+}
+
+// verifyBatchPartial evaluates the batch equation for a shard of a batch.
+func verifyBatchPartial(ctx context.Context, transcripts []*merlin.Transcript, signatures []*Signature, pubkeys []*PublicKey) (*r255.Element, error) {
+	v := NewBatchVerifier()
+	for i, t := range transcripts {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if err := checkBatchItem(t, signatures[i], pubkeys[i]); err != nil {
+			return nil, err
+		}
+
+		if err := v.Add(t, signatures[i], pubkeys[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
-	return res.Equal(zero) == 1
+	return v.sum(), nil
 }
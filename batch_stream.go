@@ -0,0 +1,138 @@
+package schnorrkel
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/gtank/merlin"
+)
+
+// DefaultStreamChunkSize is the default number of signatures verified together by a
+// StreamingBatchVerifier
+const DefaultStreamChunkSize = 4096
+
+// MaxStreamFailedChunks is the number of failed chunks a StreamingBatchVerifier records.
+// Failures past the first MaxStreamFailedChunks are only counted.
+const MaxStreamFailedChunks = 64
+
+// StreamingBatchVerifier verifies an unbounded stream of signatures in constant memory.
+// Signatures are batch verified in chunks: every chunkSize signatures the pending chunk is
+// checked and discarded, and only the running result, the number of failed chunks and the
+// first MaxStreamFailedChunks failed chunks are kept.
+//
+// A StreamingBatchVerifier is safe for concurrent use by multiple goroutines.
+type StreamingBatchVerifier struct {
+	mu        sync.Mutex
+	chunkSize int
+	pending   *BatchVerifier
+	chunks    int           // number of chunks verified
+	verified  int           // number of signatures verified
+	failures  int           // number of chunks that failed verification
+	failed    []StreamChunk // first MaxStreamFailedChunks chunks that failed verification
+}
+
+// StreamChunk identifies a chunk of signatures checked by a StreamingBatchVerifier
+type StreamChunk struct {
+	// Index is the position of the chunk in the stream
+	Index int
+	// Start and End are the positions in the stream of the first signature in the chunk
+	// and one past the last signature in the chunk
+	Start, End int
+}
+
+// NewStreamingBatchVerifier returns a StreamingBatchVerifier which verifies signatures in
+// chunks of the given size
+func NewStreamingBatchVerifier(chunkSize int) (*StreamingBatchVerifier, error) {
+	if chunkSize <= 0 {
+		return nil, errors.New("chunk size must be positive")
+	}
+
+	return &StreamingBatchVerifier{
+		chunkSize: chunkSize,
+		pending:   NewBatchVerifier(),
+		failed:    []StreamChunk{},
+	}, nil
+}
+
+// Add adds a signature to the pending chunk, verifying the chunk once it is full.
+func (v *StreamingBatchVerifier) Add(t *merlin.Transcript, sig *Signature, pubkey *PublicKey) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	err := v.pending.Add(t, sig, pubkey)
+	if err != nil {
+		return err
+	}
+
+	if v.pending.Len() >= v.chunkSize {
+		v.flush()
+	}
+	return nil
+}
+
+// Flush verifies the pending chunk, even if it is not full, and returns true if it was valid.
+func (v *StreamingBatchVerifier) Flush() bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.flush()
+}
+
+func (v *StreamingBatchVerifier) flush() bool {
+	if v.pending.Len() == 0 {
+		return true
+	}
+
+	n := v.pending.Len()
+	ok := v.pending.Verify()
+	if !ok && v.failures < MaxStreamFailedChunks {
+		v.failed = append(v.failed, StreamChunk{
+			Index: v.chunks,
+			Start: v.verified,
+			End:   v.verified + n,
+		})
+	}
+
+	if !ok {
+		v.failures++
+	}
+
+	v.chunks++
+	v.verified += n
+	v.pending.Reset()
+	return ok
+}
+
+// Verify flushes the pending chunk and returns true if every signature added so far is valid.
+func (v *StreamingBatchVerifier) Verify() bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.flush()
+	return v.failures == 0
+}
+
+// FailedChunks returns the first MaxStreamFailedChunks chunks that failed verification so far.
+// Use Failures for the total number of failed chunks.
+func (v *StreamingBatchVerifier) FailedChunks() []StreamChunk {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return append([]StreamChunk{}, v.failed...)
+}
+
+// Failures returns the number of chunks that failed verification so far.
+func (v *StreamingBatchVerifier) Failures() int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.failures
+}
+
+// ChunkSize returns the number of signatures verified together.
+func (v *StreamingBatchVerifier) ChunkSize() int {
+	return v.chunkSize
+}
+
+// Len returns the number of signatures added to the verifier.
+func (v *StreamingBatchVerifier) Len() int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.verified + v.pending.Len()
+}
@@ -0,0 +1,96 @@
+package schnorrkel_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ChainSafe/go-schnorrkel"
+
+	"github.com/gtank/merlin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewStreamingBatchVerifier_InvalidChunkSize(t *testing.T) {
+	_, err := schnorrkel.NewStreamingBatchVerifier(0)
+	require.Error(t, err)
+}
+
+func TestStreamingBatchVerifier(t *testing.T) {
+	v, err := schnorrkel.NewStreamingBatchVerifier(4)
+	require.NoError(t, err)
+
+	transcripts, sigs, pubkeys := newBatchInputs(t, 10)
+	for i := range transcripts {
+		err = v.Add(transcripts[i], sigs[i], pubkeys[i])
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, 10, v.Len())
+	require.True(t, v.Verify())
+	require.Empty(t, v.FailedChunks())
+	require.Zero(t, v.Failures())
+}
+
+func TestStreamingBatchVerifier_FailedChunk(t *testing.T) {
+	v, err := schnorrkel.NewStreamingBatchVerifier(4)
+	require.NoError(t, err)
+
+	transcripts, sigs, pubkeys := newBatchInputs(t, 10)
+	transcripts[5] = merlin.NewTranscript(fmt.Sprintf("hello_%d", 999))
+	for i := range transcripts {
+		err = v.Add(transcripts[i], sigs[i], pubkeys[i])
+		require.NoError(t, err)
+	}
+
+	require.False(t, v.Verify())
+	require.Equal(t, []schnorrkel.StreamChunk{{Index: 1, Start: 4, End: 8}}, v.FailedChunks())
+
+	// later chunks are still verified and the result stays failed
+	transcripts, sigs, pubkeys = newBatchInputs(t, 3)
+	for i := range transcripts {
+		err = v.Add(transcripts[i], sigs[i], pubkeys[i])
+		require.NoError(t, err)
+	}
+
+	require.True(t, v.Flush())
+	require.False(t, v.Verify())
+	require.Equal(t, 13, v.Len())
+}
+
+func TestStreamingBatchVerifier_Flush(t *testing.T) {
+	v, err := schnorrkel.NewStreamingBatchVerifier(8)
+	require.NoError(t, err)
+
+	transcripts, sigs, pubkeys := newBatchInputs(t, 6)
+	transcripts[4] = merlin.NewTranscript(fmt.Sprintf("hello_%d", 999))
+	for i := range transcripts {
+		err = v.Add(transcripts[i], sigs[i], pubkeys[i])
+		require.NoError(t, err)
+
+		if i == 2 {
+			require.True(t, v.Flush())
+		}
+	}
+
+	require.False(t, v.Flush())
+	require.Equal(t, []schnorrkel.StreamChunk{{Index: 1, Start: 3, End: 6}}, v.FailedChunks())
+	require.False(t, v.Verify())
+}
+
+func TestStreamingBatchVerifier_MaxFailedChunks(t *testing.T) {
+	v, err := schnorrkel.NewStreamingBatchVerifier(1)
+	require.NoError(t, err)
+
+	n := schnorrkel.MaxStreamFailedChunks + 2
+	transcripts, sigs, pubkeys := newBatchInputs(t, n)
+	for i := range transcripts {
+		err = v.Add(merlin.NewTranscript(fmt.Sprintf("hello_%d", 999)), sigs[i], pubkeys[i])
+		require.NoError(t, err)
+	}
+
+	require.False(t, v.Verify())
+	require.Equal(t, n, v.Failures())
+	failed := v.FailedChunks()
+	require.Len(t, failed, schnorrkel.MaxStreamFailedChunks)
+	require.Equal(t, schnorrkel.StreamChunk{Index: 0, Start: 0, End: 1}, failed[0])
+}
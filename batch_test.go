@@ -1,7 +1,9 @@
 package schnorrkel_test
 
 import (
+	"context"
 	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/ChainSafe/go-schnorrkel"
@@ -126,6 +128,23 @@ func TestBatchVerify_Bad(t *testing.T) {
 	require.False(t, ok)
 }
 
+func TestBatchVerify_NilInputs(t *testing.T) {
+	transcripts, sigs, pubkeys := newBatchInputs(t, 4)
+	transcripts[2] = nil
+	_, err := schnorrkel.VerifyBatch(transcripts, sigs, pubkeys)
+	require.EqualError(t, err, "transcript provided was nil")
+
+	transcripts, sigs, pubkeys = newBatchInputs(t, 4)
+	pubkeys[2] = nil
+	_, err = schnorrkel.VerifyBatch(transcripts, sigs, pubkeys)
+	require.EqualError(t, err, "public key provided was nil")
+
+	transcripts, sigs, pubkeys = newBatchInputs(t, 4)
+	sigs[2] = nil
+	_, err = schnorrkel.VerifyBatch(transcripts, sigs, pubkeys)
+	require.EqualError(t, err, "signature provided was nil")
+}
+
 func TestBatchVerifier(t *testing.T) {
 	num := 16
 	v := schnorrkel.NewBatchVerifier()
@@ -146,3 +165,299 @@ func TestBatchVerifier(t *testing.T) {
 	ok := v.Verify()
 	require.True(t, ok)
 }
+
+func newBatchInputs(t *testing.T, num int) ([]*merlin.Transcript, []*schnorrkel.Signature, []*schnorrkel.PublicKey) {
+	transcripts := make([]*merlin.Transcript, num)
+	sigs := make([]*schnorrkel.Signature, num)
+	pubkeys := make([]*schnorrkel.PublicKey, num)
+
+	for i := 0; i < num; i++ {
+		transcript := merlin.NewTranscript(fmt.Sprintf("hello_%d", i))
+		priv, pub, err := schnorrkel.GenerateKeypair()
+		require.NoError(t, err)
+
+		sigs[i], err = priv.Sign(transcript)
+		require.NoError(t, err)
+
+		transcripts[i] = merlin.NewTranscript(fmt.Sprintf("hello_%d", i))
+		pubkeys[i] = pub
+	}
+
+	return transcripts, sigs, pubkeys
+}
+
+func TestVerifyBatchParallel(t *testing.T) {
+	transcripts, sigs, pubkeys := newBatchInputs(t, 67)
+	ok, err := schnorrkel.VerifyBatchParallel(context.Background(), transcripts, sigs, pubkeys)
+	require.NoError(t, err)
+	require.True(t, ok)
+}
+
+func TestVerifyBatchParallel_Bad(t *testing.T) {
+	transcripts, sigs, pubkeys := newBatchInputs(t, 67)
+	transcripts[41] = merlin.NewTranscript(fmt.Sprintf("hello_%d", 999))
+	ok, err := schnorrkel.VerifyBatchParallel(context.Background(), transcripts, sigs, pubkeys)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestVerifyBatchParallel_Cancelled(t *testing.T) {
+	transcripts, sigs, pubkeys := newBatchInputs(t, 16)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	ok, err := schnorrkel.VerifyBatchParallel(ctx, transcripts, sigs, pubkeys)
+	require.ErrorIs(t, err, context.Canceled)
+	require.False(t, ok)
+}
+
+func TestVerifyBatchParallel_RepeatedKey(t *testing.T) {
+	num := 64
+	transcripts := make([]*merlin.Transcript, num)
+	sigs := make([]*schnorrkel.Signature, num)
+	pubkeys := make([]*schnorrkel.PublicKey, num)
+
+	priv, pub, err := schnorrkel.GenerateKeypair()
+	require.NoError(t, err)
+
+	for i := 0; i < num; i++ {
+		sigs[i], err = priv.Sign(merlin.NewTranscript(fmt.Sprintf("hello_%d", i)))
+		require.NoError(t, err)
+
+		transcripts[i] = merlin.NewTranscript(fmt.Sprintf("hello_%d", i))
+		pubkeys[i] = pub
+	}
+
+	ok, err := schnorrkel.VerifyBatchParallel(context.Background(), transcripts, sigs, pubkeys)
+	require.NoError(t, err)
+	require.True(t, ok)
+}
+
+func TestVerifyBatchParallel_UnevenShards(t *testing.T) {
+	for num := 1; num <= 9; num++ {
+		transcripts, sigs, pubkeys := newBatchInputs(t, num)
+		ok, err := schnorrkel.VerifyBatchParallel(context.Background(), transcripts, sigs, pubkeys)
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+}
+
+func TestBatchVerifier_RepeatedPublicKeys(t *testing.T) {
+	num := 16
+	v := schnorrkel.NewBatchVerifier()
+	bad := schnorrkel.NewBatchVerifier()
+
+	priv, pub, err := schnorrkel.GenerateKeypair()
+	require.NoError(t, err)
+
+	for i := 0; i < num; i++ {
+		transcript := merlin.NewTranscript(fmt.Sprintf("hello_%d", i))
+		sig, err := priv.Sign(transcript)
+		require.NoError(t, err)
+
+		err = v.Add(merlin.NewTranscript(fmt.Sprintf("hello_%d", i)), sig, pub)
+		require.NoError(t, err)
+
+		label := fmt.Sprintf("hello_%d", i)
+		if i == 7 {
+			label = "hello_999"
+		}
+		err = bad.Add(merlin.NewTranscript(label), sig, pub)
+		require.NoError(t, err)
+	}
+
+	require.True(t, v.Verify())
+	require.False(t, bad.Verify())
+}
+
+var batchBenchmarkSizes = []int{64, 1024, 16384, 100000}
+
+func newBatchBenchmarkInputs(b *testing.B, num int) ([]*schnorrkel.Signature, []*schnorrkel.PublicKey) {
+	sigs := make([]*schnorrkel.Signature, num)
+	pubkeys := make([]*schnorrkel.PublicKey, num)
+
+	for i := 0; i < num; i++ {
+		priv, pub, err := schnorrkel.GenerateKeypair()
+		require.NoError(b, err)
+
+		sigs[i], err = priv.Sign(merlin.NewTranscript(fmt.Sprintf("hello_%d", i)))
+		require.NoError(b, err)
+		pubkeys[i] = pub
+	}
+
+	return sigs, pubkeys
+}
+
+func newBatchBenchmarkTranscripts(num int) []*merlin.Transcript {
+	transcripts := make([]*merlin.Transcript, num)
+	for i := range transcripts {
+		transcripts[i] = merlin.NewTranscript(fmt.Sprintf("hello_%d", i))
+	}
+	return transcripts
+}
+
+func BenchmarkVerifyBatch(b *testing.B) {
+	for _, num := range batchBenchmarkSizes {
+		b.Run(fmt.Sprintf("%d", num), func(b *testing.B) {
+			sigs, pubkeys := newBatchBenchmarkInputs(b, num)
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				transcripts := newBatchBenchmarkTranscripts(num)
+				b.StartTimer()
+
+				ok, err := schnorrkel.VerifyBatch(transcripts, sigs, pubkeys)
+				if err != nil || !ok {
+					b.Fatal("failed to batch verify signatures")
+				}
+			}
+
+			b.ReportMetric(float64(num*b.N)/b.Elapsed().Seconds(), "sigs/s")
+		})
+	}
+}
+
+func BenchmarkVerifyBatchParallel(b *testing.B) {
+	for _, num := range batchBenchmarkSizes {
+		b.Run(fmt.Sprintf("%d", num), func(b *testing.B) {
+			sigs, pubkeys := newBatchBenchmarkInputs(b, num)
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				transcripts := newBatchBenchmarkTranscripts(num)
+				b.StartTimer()
+
+				ok, err := schnorrkel.VerifyBatchParallel(context.Background(), transcripts, sigs, pubkeys)
+				if err != nil || !ok {
+					b.Fatal("failed to batch verify signatures")
+				}
+			}
+
+			b.ReportMetric(float64(num*b.N)/b.Elapsed().Seconds(), "sigs/s")
+		})
+	}
+}
+
+func addToBatchVerifier(t *testing.T, v *schnorrkel.BatchVerifier, label string) {
+	priv, pub, err := schnorrkel.GenerateKeypair()
+	require.NoError(t, err)
+
+	sig, err := priv.Sign(merlin.NewTranscript(label))
+	require.NoError(t, err)
+
+	err = v.Add(merlin.NewTranscript(label), sig, pub)
+	require.NoError(t, err)
+}
+
+func TestBatchVerifier_LenAndReset(t *testing.T) {
+	v := schnorrkel.NewBatchVerifier()
+	require.Equal(t, 0, v.Len())
+
+	for i := 0; i < 4; i++ {
+		addToBatchVerifier(t, v, fmt.Sprintf("hello_%d", i))
+	}
+	require.Equal(t, 4, v.Len())
+
+	priv, pub, err := schnorrkel.GenerateKeypair()
+	require.NoError(t, err)
+	sig, err := priv.Sign(merlin.NewTranscript("hello"))
+	require.NoError(t, err)
+	err = v.Add(merlin.NewTranscript("goodbye"), sig, pub)
+	require.NoError(t, err)
+	require.False(t, v.Verify())
+
+	v.Reset()
+	require.Equal(t, 0, v.Len())
+	require.True(t, v.Verify())
+
+	addToBatchVerifier(t, v, "hello")
+	require.Equal(t, 1, v.Len())
+	require.True(t, v.Verify())
+}
+
+func TestBatchVerifier_Merge(t *testing.T) {
+	a := schnorrkel.NewBatchVerifier()
+	b := schnorrkel.NewBatchVerifier()
+
+	priv, pub, err := schnorrkel.GenerateKeypair()
+	require.NoError(t, err)
+
+	for i := 0; i < 8; i++ {
+		label := fmt.Sprintf("hello_%d", i)
+		sig, err := priv.Sign(merlin.NewTranscript(label))
+		require.NoError(t, err)
+
+		// the same public key appears in both verifiers
+		v := a
+		if i%2 == 1 {
+			v = b
+		}
+		err = v.Add(merlin.NewTranscript(label), sig, pub)
+		require.NoError(t, err)
+		addToBatchVerifier(t, v, label)
+	}
+
+	err = a.Merge(b)
+	require.NoError(t, err)
+	require.Equal(t, 16, a.Len())
+	require.Equal(t, 8, b.Len())
+	require.True(t, a.Verify())
+	require.True(t, b.Verify())
+
+	// a bad signature in the merged verifier fails the merged check
+	c := schnorrkel.NewBatchVerifier()
+	sig, err := priv.Sign(merlin.NewTranscript("hello"))
+	require.NoError(t, err)
+	err = c.Add(merlin.NewTranscript("goodbye"), sig, pub)
+	require.NoError(t, err)
+
+	err = a.Merge(c)
+	require.NoError(t, err)
+	require.False(t, a.Verify())
+
+	require.Error(t, a.Merge(a))
+	require.Error(t, a.Merge(nil))
+}
+
+func TestBatchVerifier_ConcurrentAdd(t *testing.T) {
+	workers := 8
+	num := 8
+	v := schnorrkel.NewBatchVerifier()
+
+	sigs := make([][]*schnorrkel.Signature, workers)
+	pubkeys := make([][]*schnorrkel.PublicKey, workers)
+	for w := 0; w < workers; w++ {
+		sigs[w] = make([]*schnorrkel.Signature, num)
+		pubkeys[w] = make([]*schnorrkel.PublicKey, num)
+		for i := 0; i < num; i++ {
+			priv, pub, err := schnorrkel.GenerateKeypair()
+			require.NoError(t, err)
+
+			sigs[w][i], err = priv.Sign(merlin.NewTranscript(fmt.Sprintf("hello_%d_%d", w, i)))
+			require.NoError(t, err)
+			pubkeys[w][i] = pub
+		}
+	}
+
+	errs := make(chan error, workers*num)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < num; i++ {
+				errs <- v.Add(merlin.NewTranscript(fmt.Sprintf("hello_%d_%d", w, i)), sigs[w][i], pubkeys[w][i])
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		require.NoError(t, err)
+	}
+	require.Equal(t, workers*num, v.Len())
+	require.True(t, v.Verify())
+}
@@ -0,0 +1,255 @@
+// Package beacon implements a chained VRF randomness beacon. The transcript of each epoch
+// commits to the randomness of the previous epoch, so a whole chain of entries can be
+// verified from a genesis seed and the beacon's public key.
+package beacon
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/ChainSafe/go-schnorrkel"
+	"github.com/gtank/merlin"
+)
+
+const (
+	// TranscriptLabel is the label of the VRF transcript of each epoch
+	TranscriptLabel = "VRFBeacon"
+
+	// RandomnessContext is the context used to make the epoch randomness from the VRF output
+	RandomnessContext = "beacon-randomness"
+
+	// RandomnessLength is the length in bytes of the epoch randomness
+	RandomnessLength = 32
+
+	// EntrySize is the length in bytes of an encoded Entry
+	EntrySize = 8 + schnorrkel.VrfSignatureSize
+)
+
+var (
+	ErrInvalidProof        = errors.New("invalid beacon entry proof")
+	ErrNonConsecutiveEpoch = errors.New("beacon entries are not in consecutive epochs")
+	ErrInvalidChainLength  = errors.New("invalid encoded chain length")
+)
+
+// Randomness is the randomness of a beacon epoch, or the genesis seed
+type Randomness [RandomnessLength]byte
+
+// Entry is the VRF output and proof of a beacon epoch
+type Entry struct {
+	Epoch     uint64
+	Signature *schnorrkel.VrfSignature
+}
+
+// Beacon produces consecutive beacon entries with a keypair
+type Beacon struct {
+	kp       *schnorrkel.Keypair
+	epoch    uint64
+	previous Randomness
+}
+
+// MakeTranscript returns the VRF transcript for the epoch, committing to the randomness of the
+// previous epoch
+func MakeTranscript(epoch uint64, previous Randomness) *merlin.Transcript {
+	t := merlin.NewTranscript(TranscriptLabel)
+	b := [8]byte{}
+	binary.LittleEndian.PutUint64(b[:], epoch)
+	t.AppendMessage([]byte("epoch"), b[:])
+	t.AppendMessage([]byte("previous randomness"), previous[:])
+	return t
+}
+
+// NewEntry signs the beacon entry for the epoch with the keypair, and returns it together with
+// the epoch's randomness
+func NewEntry(kp *schnorrkel.Keypair, epoch uint64, previous Randomness) (*Entry, Randomness, error) {
+	if kp == nil {
+		return nil, Randomness{}, errors.New("keypair provided is nil")
+	}
+
+	inout, proof, err := kp.VrfSign(MakeTranscript(epoch, previous))
+	if err != nil {
+		return nil, Randomness{}, err
+	}
+
+	randomness, err := makeRandomness(inout)
+	if err != nil {
+		return nil, Randomness{}, err
+	}
+
+	return &Entry{
+		Epoch:     epoch,
+		Signature: schnorrkel.NewVrfSignature(inout.Output(), proof),
+	}, randomness, nil
+}
+
+// Verify verifies the entry against the beacon's public key and the previous epoch's randomness,
+// and returns the entry's randomness
+func (e *Entry) Verify(pub *schnorrkel.PublicKey, previous Randomness) (Randomness, error) {
+	if pub == nil {
+		return Randomness{}, errors.New("public key provided is nil")
+	}
+
+	if e.Signature == nil {
+		return Randomness{}, errors.New("entry signature is nil")
+	}
+
+	inout, ok, err := pub.VrfVerifyInOut(MakeTranscript(e.Epoch, previous), e.Signature.Output(), e.Signature.Proof())
+	if err != nil {
+		return Randomness{}, err
+	}
+
+	if !ok {
+		return Randomness{}, fmt.Errorf("%w: epoch %d", ErrInvalidProof, e.Epoch)
+	}
+
+	return makeRandomness(inout)
+}
+
+// VerifyChain verifies a chain of entries in consecutive epochs, starting from the genesis
+// randomness that precedes the first entry, and returns the randomness of the last entry.
+// The genesis is the beacon's genesis seed when the chain starts at epoch 0, or else the
+// trusted randomness of the epoch before the first entry.
+func VerifyChain(pub *schnorrkel.PublicKey, genesis Randomness, entries []*Entry) (Randomness, error) {
+	randomness := genesis
+	for i, e := range entries {
+		if e == nil {
+			return Randomness{}, errors.New("entry provided is nil")
+		}
+
+		if i > 0 && !consecutive(entries[i-1].Epoch, e.Epoch) {
+			return Randomness{}, ErrNonConsecutiveEpoch
+		}
+
+		var err error
+		randomness, err = e.Verify(pub, randomness)
+		if err != nil {
+			return Randomness{}, err
+		}
+	}
+
+	return randomness, nil
+}
+
+// Encode returns the encoding of the entry: the epoch as u64 LE followed by the VRF signature
+func (e *Entry) Encode() [EntrySize]byte {
+	enc := [EntrySize]byte{}
+	binary.LittleEndian.PutUint64(enc[:8], e.Epoch)
+	sig := e.Signature.Encode()
+	copy(enc[8:], sig[:])
+	return enc
+}
+
+// Decode sets the Entry to the decoded input
+func (e *Entry) Decode(in [EntrySize]byte) error {
+	sigb := [schnorrkel.VrfSignatureSize]byte{}
+	copy(sigb[:], in[8:])
+	sig := new(schnorrkel.VrfSignature)
+	err := sig.Decode(sigb)
+	if err != nil {
+		return err
+	}
+
+	e.Epoch = binary.LittleEndian.Uint64(in[:8])
+	e.Signature = sig
+	return nil
+}
+
+// EncodeChain encodes a chain of entries in consecutive epochs compactly, as the epoch of the
+// first entry as u64 LE followed by the VRF signature of each entry
+func EncodeChain(entries []*Entry) ([]byte, error) {
+	if len(entries) == 0 {
+		return nil, errors.New("no entries provided")
+	}
+
+	enc := make([]byte, 8, 8+len(entries)*schnorrkel.VrfSignatureSize)
+	binary.LittleEndian.PutUint64(enc, entries[0].Epoch)
+	for i, e := range entries {
+		if i > 0 && !consecutive(entries[i-1].Epoch, e.Epoch) {
+			return nil, ErrNonConsecutiveEpoch
+		}
+
+		sig := e.Signature.Encode()
+		enc = append(enc, sig[:]...)
+	}
+
+	return enc, nil
+}
+
+// DecodeChain decodes a chain of entries encoded with EncodeChain
+func DecodeChain(in []byte) ([]*Entry, error) {
+	if len(in) < 8 || (len(in)-8)%schnorrkel.VrfSignatureSize != 0 {
+		return nil, ErrInvalidChainLength
+	}
+
+	start := binary.LittleEndian.Uint64(in[:8])
+	entries := make([]*Entry, (len(in)-8)/schnorrkel.VrfSignatureSize)
+	if len(entries) > 0 && uint64(len(entries)-1) > math.MaxUint64-start {
+		return nil, ErrNonConsecutiveEpoch
+	}
+
+	for i := range entries {
+		sigb := [schnorrkel.VrfSignatureSize]byte{}
+		copy(sigb[:], in[8+i*schnorrkel.VrfSignatureSize:])
+		sig := new(schnorrkel.VrfSignature)
+		err := sig.Decode(sigb)
+		if err != nil {
+			return nil, err
+		}
+
+		entries[i] = &Entry{
+			Epoch:     start + uint64(i),
+			Signature: sig,
+		}
+	}
+
+	return entries, nil
+}
+
+// NewBeacon creates a beacon that produces entries with the keypair, starting at epoch 0
+// from the genesis seed
+func NewBeacon(kp *schnorrkel.Keypair, genesis Randomness) *Beacon {
+	return &Beacon{
+		kp:       kp,
+		previous: genesis,
+	}
+}
+
+// Next produces the entry for the next epoch
+func (b *Beacon) Next() (*Entry, error) {
+	e, randomness, err := NewEntry(b.kp, b.epoch, b.previous)
+	if err != nil {
+		return nil, err
+	}
+
+	b.epoch++
+	b.previous = randomness
+	return e, nil
+}
+
+// Epoch returns the epoch of the next entry
+func (b *Beacon) Epoch() uint64 {
+	return b.epoch
+}
+
+// Randomness returns the randomness of the latest entry, or the genesis seed if none was produced
+func (b *Beacon) Randomness() Randomness {
+	return b.previous
+}
+
+// consecutive returns whether next is the epoch after prev, which is never the case for the
+// last epoch
+func consecutive(prev, next uint64) bool {
+	return prev != math.MaxUint64 && next == prev+1
+}
+
+func makeRandomness(inout *schnorrkel.VrfInOut) (Randomness, error) {
+	b, err := inout.MakeBytes(RandomnessLength, []byte(RandomnessContext))
+	if err != nil {
+		return Randomness{}, err
+	}
+
+	randomness := Randomness{}
+	copy(randomness[:], b)
+	return randomness, nil
+}
@@ -0,0 +1,128 @@
+package beacon
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ChainSafe/go-schnorrkel"
+	"github.com/stretchr/testify/require"
+)
+
+var testGenesis = Randomness{1, 2, 3, 4}
+
+func newTestChain(t *testing.T, n int) (*schnorrkel.PublicKey, []*Entry, Randomness) {
+	priv, pub, err := schnorrkel.GenerateKeypair()
+	require.NoError(t, err)
+
+	b := NewBeacon(schnorrkel.NewKeypair(pub, priv), testGenesis)
+	require.Equal(t, testGenesis, b.Randomness())
+
+	entries := make([]*Entry, n)
+	for i := range entries {
+		entries[i], err = b.Next()
+		require.NoError(t, err)
+		require.Equal(t, uint64(i), entries[i].Epoch)
+	}
+	require.Equal(t, uint64(n), b.Epoch())
+
+	return pub, entries, b.Randomness()
+}
+
+func TestVerifyChain(t *testing.T) {
+	pub, entries, last := newTestChain(t, 30)
+
+	randomness, err := VerifyChain(pub, testGenesis, entries)
+	require.NoError(t, err)
+	require.Equal(t, last, randomness)
+
+	// a chain can also be verified from a trusted checkpoint
+	checkpoint, err := VerifyChain(pub, testGenesis, entries[:10])
+	require.NoError(t, err)
+	randomness, err = VerifyChain(pub, checkpoint, entries[10:])
+	require.NoError(t, err)
+	require.Equal(t, last, randomness)
+
+	_, err = VerifyChain(pub, Randomness{}, entries)
+	require.ErrorIs(t, err, ErrInvalidProof)
+
+	_, other, err := schnorrkel.GenerateKeypair()
+	require.NoError(t, err)
+	_, err = VerifyChain(other, testGenesis, entries)
+	require.ErrorIs(t, err, ErrInvalidProof)
+
+	swapped := append([]*Entry{}, entries...)
+	swapped[3], swapped[4] = swapped[4], swapped[3]
+	_, err = VerifyChain(pub, testGenesis, swapped)
+	require.ErrorIs(t, err, ErrNonConsecutiveEpoch)
+
+	// an entry out of its place in the chain does not verify even with a consecutive epoch
+	moved := &Entry{Epoch: 4, Signature: entries[5].Signature}
+	_, err = VerifyChain(pub, testGenesis, append(append([]*Entry{}, entries[:4]...), moved))
+	require.ErrorIs(t, err, ErrInvalidProof)
+}
+
+func TestEntry_Encode(t *testing.T) {
+	pub, entries, _ := newTestChain(t, 1)
+
+	dec := new(Entry)
+	require.NoError(t, dec.Decode(entries[0].Encode()))
+	require.Equal(t, entries[0].Encode(), dec.Encode())
+
+	_, err := dec.Verify(pub, testGenesis)
+	require.NoError(t, err)
+}
+
+func TestEncodeChain(t *testing.T) {
+	pub, entries, last := newTestChain(t, 12)
+
+	enc, err := EncodeChain(entries[2:])
+	require.NoError(t, err)
+	require.Len(t, enc, 8+10*schnorrkel.VrfSignatureSize)
+
+	dec, err := DecodeChain(enc)
+	require.NoError(t, err)
+	require.Len(t, dec, 10)
+	require.Equal(t, uint64(2), dec[0].Epoch)
+
+	checkpoint, err := VerifyChain(pub, testGenesis, entries[:2])
+	require.NoError(t, err)
+	randomness, err := VerifyChain(pub, checkpoint, dec)
+	require.NoError(t, err)
+	require.Equal(t, last, randomness)
+
+	_, err = DecodeChain(enc[:len(enc)-1])
+	require.ErrorIs(t, err, ErrInvalidChainLength)
+
+	_, err = EncodeChain([]*Entry{entries[0], entries[2]})
+	require.ErrorIs(t, err, ErrNonConsecutiveEpoch)
+}
+
+func TestVerifyChain_EpochOverflow(t *testing.T) {
+	priv, pub, err := schnorrkel.GenerateKeypair()
+	require.NoError(t, err)
+	kp := schnorrkel.NewKeypair(pub, priv)
+
+	last, randomness, err := NewEntry(kp, math.MaxUint64, testGenesis)
+	require.NoError(t, err)
+	wrapped, _, err := NewEntry(kp, 0, randomness)
+	require.NoError(t, err)
+
+	// the epoch after the last one is not epoch 0
+	_, err = VerifyChain(pub, testGenesis, []*Entry{last, wrapped})
+	require.ErrorIs(t, err, ErrNonConsecutiveEpoch)
+
+	_, err = EncodeChain([]*Entry{last, wrapped})
+	require.ErrorIs(t, err, ErrNonConsecutiveEpoch)
+
+	enc, err := EncodeChain([]*Entry{last})
+	require.NoError(t, err)
+	dec, err := DecodeChain(enc)
+	require.NoError(t, err)
+	verified, err := VerifyChain(pub, testGenesis, dec)
+	require.NoError(t, err)
+	require.Equal(t, randomness, verified)
+
+	sig := wrapped.Signature.Encode()
+	_, err = DecodeChain(append(enc, sig[:]...))
+	require.ErrorIs(t, err, ErrNonConsecutiveEpoch)
+}
@@ -0,0 +1,226 @@
+package schnorrkel
+
+import (
+	"container/list"
+	"errors"
+	"sync"
+
+	"github.com/gtank/merlin"
+	r255 "github.com/gtank/ristretto255"
+	"golang.org/x/crypto/blake2b"
+)
+
+// VerificationCache is a fixed-size LRU cache of successfully verified signatures.
+// Entries are keyed by a blake2b-256 digest of the public key, the transcript challenge
+// and the encoded signature, so a cached entry can only be hit by the exact signature over
+// the exact message that was verified before. Failed verifications are never cached.
+//
+// A VerificationCache is safe for concurrent use by multiple goroutines.
+type VerificationCache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[[32]byte]*list.Element
+	lru     *list.List // most recently used at the front
+	hits    uint64
+	misses  uint64
+}
+
+// NewVerificationCache returns a VerificationCache holding at most size entries
+func NewVerificationCache(size int) (*VerificationCache, error) {
+	if size <= 0 {
+		return nil, errors.New("cache size must be positive")
+	}
+
+	return &VerificationCache{
+		size:    size,
+		entries: make(map[[32]byte]*list.Element),
+		lru:     list.New(),
+	}, nil
+}
+
+// Verify verifies a schnorr signature like PublicKey.Verify, skipping the verification
+// if the signature has been verified by this cache before.
+func (c *VerificationCache) Verify(publicKey *PublicKey, s *Signature, t *merlin.Transcript) (bool, error) {
+	if publicKey == nil {
+		return false, errors.New("public key provided is nil")
+	}
+
+	if s == nil {
+		return false, errors.New("signature provided is nil")
+	}
+
+	if t == nil {
+		return false, errors.New("transcript provided is nil")
+	}
+
+	if publicKey.key.Equal(publicKeyAtInfinity) == 1 {
+		return false, ErrPublicKeyAtInfinity
+	}
+
+	pubc := [PublicKeySize]byte{}
+	copy(pubc[:], publicKey.key.Encode([]byte{}))
+	k := signatureChallenge(t, s, pubc)
+	d := verificationDigest(pubc, k, s)
+
+	if c.lookup(d) {
+		return true, nil
+	}
+
+	if !publicKey.verifyWithChallenge(s, k) {
+		return false, nil
+	}
+
+	c.insert(d)
+	return true, nil
+}
+
+// NewBatchVerifier returns a batch verifier which skips signatures found in the cache
+// and adds the signatures it verifies to the cache
+func (c *VerificationCache) NewBatchVerifier() *CachedBatchVerifier {
+	return &CachedBatchVerifier{
+		cache:    c,
+		verifier: NewBatchVerifier(),
+		pending:  [][32]byte{},
+	}
+}
+
+// Len returns the number of entries in the cache.
+func (c *VerificationCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// Hits returns the number of verifications answered from the cache.
+func (c *VerificationCache) Hits() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits
+}
+
+// Misses returns the number of verifications not found in the cache.
+func (c *VerificationCache) Misses() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.misses
+}
+
+// Purge removes all entries from the cache. The hit and miss counters are kept.
+func (c *VerificationCache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[[32]byte]*list.Element)
+	c.lru.Init()
+}
+
+func (c *VerificationCache) lookup(d [32]byte) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[d]
+	if !ok {
+		c.misses++
+		return false
+	}
+
+	c.hits++
+	c.lru.MoveToFront(e)
+	return true
+}
+
+func (c *VerificationCache) insert(d [32]byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[d]; ok {
+		c.lru.MoveToFront(e)
+		return
+	}
+
+	c.entries[d] = c.lru.PushFront(d)
+	if c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.([32]byte))
+	}
+}
+
+// verificationDigest returns the cache key blake2b-256(P || k || signature)
+func verificationDigest(pubc [PublicKeySize]byte, k *r255.Scalar, s *Signature) [32]byte {
+	sigc := s.Encode()
+	b := make([]byte, 0, PublicKeySize+32+SignatureSize)
+	b = append(b, pubc[:]...)
+	b = k.Encode(b)
+	b = append(b, sigc[:]...)
+	return blake2b.Sum256(b)
+}
+
+// CachedBatchVerifier is a BatchVerifier backed by a VerificationCache.
+// Signatures already in the cache are not added to the batch, and once the batch verifies
+// successfully all of its signatures are added to the cache.
+//
+// A CachedBatchVerifier is safe for concurrent use by multiple goroutines.
+type CachedBatchVerifier struct {
+	mu       sync.Mutex
+	cache    *VerificationCache
+	verifier *BatchVerifier
+	pending  [][32]byte // digests of the signatures in verifier
+}
+
+// Add adds a signature to the batch, unless it is found in the cache.
+func (v *CachedBatchVerifier) Add(t *merlin.Transcript, sig *Signature, pubkey *PublicKey) error {
+	if t == nil {
+		return errors.New("provided transcript is nil")
+	}
+
+	if sig == nil {
+		return errors.New("provided signature is nil")
+	}
+
+	if pubkey == nil {
+		return errors.New("provided public key is nil")
+	}
+
+	pubc := [PublicKeySize]byte{}
+	copy(pubc[:], pubkey.key.Encode([]byte{}))
+	k := signatureChallenge(t, sig, pubc)
+	d := verificationDigest(pubc, k, sig)
+
+	if v.cache.lookup(d) {
+		return nil
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	err := v.verifier.addWithChallenge(sig, pubkey, pubc, k)
+	if err != nil {
+		return err
+	}
+
+	v.pending = append(v.pending, d)
+	return nil
+}
+
+// Verify batch verifies the signatures not found in the cache, adding them to the cache
+// if they are all valid.
+func (v *CachedBatchVerifier) Verify() bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if !v.verifier.Verify() {
+		return false
+	}
+
+	for _, d := range v.pending {
+		v.cache.insert(d)
+	}
+	return true
+}
+
+// Len returns the number of signatures in the batch which were not found in the cache.
+func (v *CachedBatchVerifier) Len() int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return len(v.pending)
+}
@@ -0,0 +1,162 @@
+package schnorrkel_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/ChainSafe/go-schnorrkel"
+
+	"github.com/gtank/merlin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewVerificationCache_InvalidSize(t *testing.T) {
+	_, err := schnorrkel.NewVerificationCache(0)
+	require.Error(t, err)
+}
+
+func TestVerificationCache_Verify(t *testing.T) {
+	c, err := schnorrkel.NewVerificationCache(8)
+	require.NoError(t, err)
+
+	priv, pub, err := schnorrkel.GenerateKeypair()
+	require.NoError(t, err)
+
+	sig, err := priv.Sign(schnorrkel.NewSigningContext([]byte("test"), []byte("hello")))
+	require.NoError(t, err)
+
+	ok, err := c.Verify(pub, sig, schnorrkel.NewSigningContext([]byte("test"), []byte("hello")))
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, uint64(0), c.Hits())
+	require.Equal(t, uint64(1), c.Misses())
+	require.Equal(t, 1, c.Len())
+
+	ok, err = c.Verify(pub, sig, schnorrkel.NewSigningContext([]byte("test"), []byte("hello")))
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, uint64(1), c.Hits())
+	require.Equal(t, uint64(1), c.Misses())
+
+	// a different message misses the cache and fails, and is not cached
+	for i := 0; i < 2; i++ {
+		ok, err = c.Verify(pub, sig, schnorrkel.NewSigningContext([]byte("test"), []byte("goodbye")))
+		require.NoError(t, err)
+		require.False(t, ok)
+	}
+	require.Equal(t, uint64(1), c.Hits())
+	require.Equal(t, uint64(3), c.Misses())
+	require.Equal(t, 1, c.Len())
+
+	c.Purge()
+	require.Equal(t, 0, c.Len())
+}
+
+func TestVerificationCache_Eviction(t *testing.T) {
+	c, err := schnorrkel.NewVerificationCache(2)
+	require.NoError(t, err)
+
+	priv, pub, err := schnorrkel.GenerateKeypair()
+	require.NoError(t, err)
+
+	sigs := make([]*schnorrkel.Signature, 3)
+	for i := range sigs {
+		sigs[i], err = priv.Sign(merlin.NewTranscript(fmt.Sprintf("hello_%d", i)))
+		require.NoError(t, err)
+	}
+
+	verify := func(i int) {
+		ok, err := c.Verify(pub, sigs[i], merlin.NewTranscript(fmt.Sprintf("hello_%d", i)))
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+
+	verify(0)
+	verify(1)
+	verify(0) // hit, 1 is now least recently used
+	verify(2) // evicts 1
+	require.Equal(t, 2, c.Len())
+	require.Equal(t, uint64(1), c.Hits())
+
+	verify(0)
+	require.Equal(t, uint64(2), c.Hits())
+	verify(1)
+	require.Equal(t, uint64(2), c.Hits())
+	require.Equal(t, uint64(4), c.Misses())
+}
+
+func TestCachedBatchVerifier(t *testing.T) {
+	c, err := schnorrkel.NewVerificationCache(64)
+	require.NoError(t, err)
+
+	transcripts, sigs, pubkeys := newBatchInputs(t, 16)
+	ok, err := c.Verify(pubkeys[3], sigs[3], transcripts[3])
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	v := c.NewBatchVerifier()
+	for i := range sigs {
+		err = v.Add(merlin.NewTranscript(fmt.Sprintf("hello_%d", i)), sigs[i], pubkeys[i])
+		require.NoError(t, err)
+	}
+	require.Equal(t, 15, v.Len())
+	require.True(t, v.Verify())
+	require.Equal(t, 16, c.Len())
+
+	// every signature is now answered from the cache
+	v = c.NewBatchVerifier()
+	for i := range sigs {
+		err = v.Add(merlin.NewTranscript(fmt.Sprintf("hello_%d", i)), sigs[i], pubkeys[i])
+		require.NoError(t, err)
+	}
+	require.Equal(t, 0, v.Len())
+	require.True(t, v.Verify())
+}
+
+func TestCachedBatchVerifier_Bad(t *testing.T) {
+	c, err := schnorrkel.NewVerificationCache(64)
+	require.NoError(t, err)
+
+	_, sigs, pubkeys := newBatchInputs(t, 16)
+	v := c.NewBatchVerifier()
+	for i := range sigs {
+		label := fmt.Sprintf("hello_%d", i)
+		if i == 9 {
+			label = "hello_999"
+		}
+		err = v.Add(merlin.NewTranscript(label), sigs[i], pubkeys[i])
+		require.NoError(t, err)
+	}
+
+	require.False(t, v.Verify())
+	require.Equal(t, 0, c.Len())
+}
+
+func TestVerificationCache_Concurrent(t *testing.T) {
+	c, err := schnorrkel.NewVerificationCache(4)
+	require.NoError(t, err)
+
+	_, sigs, pubkeys := newBatchInputs(t, 8)
+
+	results := make(chan bool, 8*len(sigs))
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range sigs {
+				ok, err := c.Verify(pubkeys[i], sigs[i], merlin.NewTranscript(fmt.Sprintf("hello_%d", i)))
+				results <- ok && err == nil
+			}
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	for ok := range results {
+		require.True(t, ok)
+	}
+	require.Equal(t, uint64(8*len(sigs)), c.Hits()+c.Misses())
+	require.Equal(t, 4, c.Len())
+}
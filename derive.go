@@ -1,7 +1,6 @@
 package schnorrkel
 
 import (
-	"crypto/rand"
 	"errors"
 
 	"github.com/gtank/merlin"
@@ -24,8 +23,12 @@ type DerivableKey interface {
 // ExtendedKey consists of a DerivableKey which can be a schnorrkel public or private key
 // as well as chain code
 type ExtendedKey struct {
-	key       DerivableKey
-	chaincode [ChainCodeLength]byte
+	key               DerivableKey
+	chaincode         [ChainCodeLength]byte
+	path              DerivationPath
+	depth             int
+	parentFingerprint [FingerprintLength]byte
+	child             ChildJunction
 }
 
 // NewExtendedKey creates an ExtendedKey given a DerivableKey and chain code
@@ -69,9 +72,34 @@ func (ek *ExtendedKey) Public() (*PublicKey, error) {
 	return nil, errors.New("extended key is not a valid public or private key")
 }
 
-// DeriveKey derives an extended key from an extended key
+// DeriveKey derives a soft child of the extended key from the transcript and the key's chain
+// code. The child's depth and parent fingerprint are set for Encode. As the transcript has no
+// junction, the child's ID is its chain code, which is the same whether the parent is a secret or
+// a public key.
 func (ek *ExtendedKey) DeriveKey(t *merlin.Transcript) (*ExtendedKey, error) {
-	return ek.key.DeriveKey(t, ek.chaincode)
+	if t == nil {
+		return nil, errors.New("transcript provided is nil")
+	}
+
+	derived, err := ek.key.DeriveKey(t, ek.chaincode)
+	if err != nil {
+		return nil, err
+	}
+
+	parent := ek
+	if msk, ok := ek.key.(*MiniSecretKey); ok {
+		parent = NewExtendedKey(msk.ExpandEd25519(), ek.chaincode)
+	}
+
+	fp, err := parent.Fingerprint()
+	if err != nil {
+		return nil, err
+	}
+
+	derived.depth = ek.depth + 1
+	derived.parentFingerprint = fp
+	derived.child.ID = derived.chaincode
+	return derived, nil
 }
 
 // HardDeriveMiniSecretKey implements BIP-32 like "hard" derivation of a mini
@@ -131,13 +159,7 @@ func (secretKey *SecretKey) DeriveKey(t *merlin.Transcript, cc [ChainCodeLength]
 		return nil, err
 	}
 
-	// TODO: need transcript RNG to match rust-schnorrkel
-	// see: https://github.com/w3f/schnorrkel/blob/798ab3e0813aa478b520c5cf6dc6e02fd4e07f0a/src/derive.rs#L186
-	nonce := [32]byte{}
-	_, err = rand.Read(nonce[:])
-	if err != nil {
-		return nil, err
-	}
+	nonce := secretKey.deriveNonce(t)
 
 	dsk, err := ScalarFromBytes(secretKey.key)
 	if err != nil {
@@ -160,6 +182,23 @@ func (secretKey *SecretKey) DeriveKey(t *merlin.Transcript, cc [ChainCodeLength]
 	}, nil
 }
 
+// deriveNonce derives the nonce of a soft-derived secret key from the derivation transcript,
+// after the scalar and chain code were extracted from it. This is specific to this package:
+// rust-schnorrkel's derived_key_simple draws the nonce from randomized witness bytes, so its
+// nonces cannot be reproduced, while here the same derivation always gives the same nonce.
+// Only public bytes are extracted from the caller's transcript, and they are committed together
+// with the parent secret key and nonce in a separate transcript.
+func (secretKey *SecretKey) deriveNonce(t *merlin.Transcript) [32]byte {
+	nt := merlin.NewTranscript("SchnorrRistrettoHDKD-nonce")
+	nt.AppendMessage([]byte("derivation"), t.ExtractBytes([]byte("HDKD-nonce"), 32))
+	nt.AppendMessage([]byte("secret-key"), secretKey.key[:])
+	nt.AppendMessage([]byte("nonce"), secretKey.nonce[:])
+
+	nonce := [32]byte{}
+	copy(nonce[:], nt.ExtractBytes([]byte("HDKD-nonce"), 32))
+	return nonce
+}
+
 // HardDeriveMiniSecretKey implements BIP-32 like "hard" derivation of a mini
 // secret from a secret key
 func (secretKey *SecretKey) HardDeriveMiniSecretKey(i []byte, cc [ChainCodeLength]byte) (
@@ -0,0 +1,191 @@
+package schnorrkel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/gtank/merlin"
+	r255 "github.com/gtank/ristretto255"
+)
+
+// DeriveKeysSoft derives the child of the extended key for each soft junction, as DerivePath
+// does one at a time. The parent public key, secret scalar and fingerprint are computed once,
+// and the junctions are sharded across GOMAXPROCS goroutines.
+func (ek *ExtendedKey) DeriveKeysSoft(ctx context.Context, junctions []Junction) ([]*ExtendedKey, error) {
+	p, err := ek.newBulkParent(junctions)
+	if err != nil {
+		return nil, err
+	}
+
+	children := make([]*ExtendedKey, len(junctions))
+	err = deriveParallel(ctx, len(junctions), func(i int) error {
+		j := junctions[i]
+		sc, cc, t := p.deriveScalarAndChaincode(j)
+
+		var key DerivableKey
+		if p.secret == nil {
+			key = &PublicKey{key: r255.NewElement().Add(p.pub.key, r255.NewElement().ScalarBaseMult(sc))}
+		} else {
+			dsk := r255.NewScalar().Add(p.secret, sc)
+			sk := &SecretKey{
+				nonce: p.secretKey.deriveNonce(t),
+			}
+			copy(sk.key[:], dsk.Encode([]byte{}))
+			key = sk
+		}
+
+		path := make(DerivationPath, 0, len(ek.path)+1)
+		path = append(path, ek.path...)
+		children[i] = &ExtendedKey{
+			key:               key,
+			chaincode:         cc,
+			path:              append(path, j),
+			depth:             ek.depth + 1,
+			parentFingerprint: p.fingerprint,
+			child:             ChildJunction{ID: j.ChainCode()},
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return children, nil
+}
+
+// DerivePublicKeysSoft returns the public key of the child of the extended key for each soft
+// junction, without deriving any secret keys. The junctions are sharded across GOMAXPROCS
+// goroutines.
+func (ek *ExtendedKey) DerivePublicKeysSoft(ctx context.Context, junctions []Junction) ([]*PublicKey, error) {
+	p, err := ek.newBulkParent(junctions)
+	if err != nil {
+		return nil, err
+	}
+
+	pubs := make([]*PublicKey, len(junctions))
+	err = deriveParallel(ctx, len(junctions), func(i int) error {
+		sc, _, _ := p.deriveScalarAndChaincode(junctions[i])
+		pubs[i] = &PublicKey{key: r255.NewElement().Add(p.pub.key, r255.NewElement().ScalarBaseMult(sc))}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pubs, nil
+}
+
+// bulkParent holds what every child derivation of an extended key shares
+type bulkParent struct {
+	pub         *PublicKey
+	fingerprint [FingerprintLength]byte
+	secretKey   *SecretKey   // nil for a public extended key
+	secret      *r255.Scalar // the scalar of secretKey
+}
+
+// newBulkParent checks that the junctions are soft and returns what their derivations share
+func (ek *ExtendedKey) newBulkParent(junctions []Junction) (*bulkParent, error) {
+	for i, j := range junctions {
+		if j.Hard {
+			return nil, fmt.Errorf("%w: hard junction %s at position %d of a soft derivation",
+				ErrInvalidDerivationPath, j, i)
+		}
+	}
+
+	// expand a mini secret key as DerivePath does, before its public key is computed
+	parent := ek
+	if msk, ok := ek.key.(*MiniSecretKey); ok {
+		parent = NewExtendedKey(msk.ExpandEd25519(), ek.chaincode)
+	}
+
+	pub, err := parent.Public()
+	if err != nil {
+		return nil, err
+	}
+
+	// cache the encoding before the workers read it concurrently
+	pub.Encode()
+
+	p := &bulkParent{
+		pub:         pub,
+		fingerprint: publicKeyFingerprint(pub),
+	}
+
+	if sk, ok := parent.key.(*SecretKey); ok {
+		p.secretKey = sk
+		p.secret, err = ScalarFromBytes(sk.key)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+// deriveScalarAndChaincode returns the scalar and chain code of the child with the soft junction,
+// derived as by DerivePath, and the derivation transcript
+func (p *bulkParent) deriveScalarAndChaincode(j Junction) (*r255.Scalar, [ChainCodeLength]byte, *merlin.Transcript) {
+	t := merlin.NewTranscript("SchnorrRistrettoHDKD")
+	t.AppendMessage([]byte("sign-bytes"), []byte{})
+	// the transcript is never nil, so this cannot fail
+	sc, cc, _ := p.pub.DeriveScalarAndChaincode(t, j.ChainCode())
+	return sc, cc, t
+}
+
+// deriveParallel calls derive for every index in [0, n), sharded across GOMAXPROCS goroutines,
+// and returns the first error
+func deriveParallel(ctx context.Context, n int, derive func(i int) error) error {
+	if ctx == nil {
+		return errors.New("context provided is nil")
+	}
+
+	if n == 0 {
+		return nil
+	}
+
+	workers := runtime.GOMAXPROCS(0)
+	if workers > n {
+		workers = n
+	}
+
+	chunk := (n + workers - 1) / workers
+	workers = (n + chunk - 1) / chunk
+	errs := make([]error, workers)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		start := w * chunk
+		end := start + chunk
+		if end > n {
+			end = n
+		}
+
+		wg.Add(1)
+		go func(w, start, end int) {
+			defer wg.Done()
+			for i := start; i < end; i++ {
+				if err := ctx.Err(); err != nil {
+					errs[w] = err
+					return
+				}
+
+				if err := derive(i); err != nil {
+					errs[w] = err
+					return
+				}
+			}
+		}(w, start, end)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,177 @@
+package schnorrkel
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var bulkBenchmarkSizes = []int{1024, 100000}
+
+func newBulkJunctions(n int) []Junction {
+	junctions := make([]Junction, n)
+	for i := range junctions {
+		junctions[i] = NewNumericJunction(uint64(i), false)
+	}
+	return junctions
+}
+
+func TestExtendedKey_DeriveKeysSoft(t *testing.T) {
+	priv, _, err := GenerateKeypair()
+	require.NoError(t, err)
+	parent, err := NewExtendedKey(priv, [ChainCodeLength]byte{3}).DerivePath(DerivationPath{{Hard: true, Name: "bulk"}})
+	require.NoError(t, err)
+	xpub, err := parent.Neuter()
+	require.NoError(t, err)
+
+	junctions := append(newBulkJunctions(37), Junction{Name: "foo"})
+	children, err := parent.DeriveKeysSoft(context.Background(), junctions)
+	require.NoError(t, err)
+	require.Len(t, children, len(junctions))
+
+	pubChildren, err := xpub.DeriveKeysSoft(context.Background(), junctions)
+	require.NoError(t, err)
+
+	pubs, err := xpub.DerivePublicKeysSoft(context.Background(), junctions)
+	require.NoError(t, err)
+
+	secretPubs, err := parent.DerivePublicKeysSoft(context.Background(), junctions)
+	require.NoError(t, err)
+
+	// a mini secret key is expanded as by DerivePath
+	msk, err := NewMiniSecretKeyFromRaw([32]byte{8})
+	require.NoError(t, err)
+	mskParent := NewExtendedKey(msk, [ChainCodeLength]byte{3})
+	mskChildren, err := mskParent.DeriveKeysSoft(context.Background(), junctions)
+	require.NoError(t, err)
+	mskPubs, err := mskParent.DerivePublicKeysSoft(context.Background(), junctions)
+	require.NoError(t, err)
+
+	for i, j := range junctions {
+		fromMsk, err := mskParent.DerivePath(DerivationPath{j})
+		require.NoError(t, err)
+		fromMskEnc, err := fromMsk.Encode()
+		require.NoError(t, err)
+		mskEnc, err := mskChildren[i].Encode()
+		require.NoError(t, err)
+		require.Equal(t, fromMskEnc, mskEnc)
+		fromMskPub, err := fromMsk.Public()
+		require.NoError(t, err)
+		require.Equal(t, fromMskPub.Encode(), mskPubs[i].Encode())
+
+		expected, err := parent.DerivePath(DerivationPath{j})
+		require.NoError(t, err)
+		require.Equal(t, ChildJunction{ID: j.ChainCode()}, children[i].Child())
+
+		// the same secret key, nonce, chain code and metadata as DerivePath
+		expectedSecret, err := expected.Secret()
+		require.NoError(t, err)
+		secret, err := children[i].Secret()
+		require.NoError(t, err)
+		require.Equal(t, expectedSecret.key, secret.key)
+		require.Equal(t, expectedSecret.nonce, secret.nonce)
+		require.Equal(t, expected.ChainCode(), children[i].ChainCode())
+		require.Equal(t, expected.Path(), children[i].Path())
+
+		expectedEnc, err := expected.Encode()
+		require.NoError(t, err)
+		enc, err := children[i].Encode()
+		require.NoError(t, err)
+		require.Equal(t, expectedEnc, enc)
+
+		expectedPub, err := expected.Public()
+		require.NoError(t, err)
+		require.Equal(t, expectedPub.Encode(), pubs[i].Encode())
+		require.Equal(t, expectedPub.Encode(), secretPubs[i].Encode())
+		require.Equal(t, expectedPub.Encode(), pubChildren[i].Key().Encode())
+		require.Equal(t, expected.ChainCode(), pubChildren[i].ChainCode())
+	}
+}
+
+func TestExtendedKey_DeriveKeysSoft_Cancelled(t *testing.T) {
+	_, pub, err := GenerateKeypair()
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = NewExtendedKey(pub, [ChainCodeLength]byte{}).DerivePublicKeysSoft(ctx, newBulkJunctions(10))
+	require.ErrorIs(t, err, context.Canceled)
+
+	pubs, err := NewExtendedKey(pub, [ChainCodeLength]byte{}).DerivePublicKeysSoft(context.Background(), nil)
+	require.NoError(t, err)
+	require.Empty(t, pubs)
+}
+
+func TestExtendedKey_DeriveKeysSoft_Hard(t *testing.T) {
+	priv, _, err := GenerateKeypair()
+	require.NoError(t, err)
+
+	junctions := append(newBulkJunctions(3), NewNumericJunction(3, true))
+	_, err = NewExtendedKey(priv, [ChainCodeLength]byte{}).DeriveKeysSoft(context.Background(), junctions)
+	require.ErrorIs(t, err, ErrInvalidDerivationPath)
+	_, err = NewExtendedKey(priv, [ChainCodeLength]byte{}).DerivePublicKeysSoft(context.Background(), junctions)
+	require.ErrorIs(t, err, ErrInvalidDerivationPath)
+}
+
+func BenchmarkDerivePublicKeysSoft(b *testing.B) {
+	_, pub, err := GenerateKeypair()
+	require.NoError(b, err)
+	ek := NewExtendedKey(pub, [ChainCodeLength]byte{})
+
+	for _, num := range bulkBenchmarkSizes {
+		b.Run(fmt.Sprintf("%d", num), func(b *testing.B) {
+			junctions := newBulkJunctions(num)
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				_, err := ek.DerivePublicKeysSoft(context.Background(), junctions)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+
+			b.ReportMetric(float64(num*b.N)/b.Elapsed().Seconds(), "keys/s")
+		})
+	}
+}
+
+func BenchmarkDeriveKeysSoft(b *testing.B) {
+	priv, _, err := GenerateKeypair()
+	require.NoError(b, err)
+	ek := NewExtendedKey(priv, [ChainCodeLength]byte{})
+
+	for _, num := range bulkBenchmarkSizes {
+		b.Run(fmt.Sprintf("%d", num), func(b *testing.B) {
+			junctions := newBulkJunctions(num)
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				_, err := ek.DeriveKeysSoft(context.Background(), junctions)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+
+			b.ReportMetric(float64(num*b.N)/b.Elapsed().Seconds(), "keys/s")
+		})
+	}
+}
+
+// BenchmarkDeriveKeySoft is the one-at-a-time baseline for BenchmarkDeriveKeysSoft
+func BenchmarkDeriveKeySoft(b *testing.B) {
+	priv, _, err := GenerateKeypair()
+	require.NoError(b, err)
+	ek := NewExtendedKey(priv, [ChainCodeLength]byte{})
+	path := DerivationPath{NewNumericJunction(0, false)}
+
+	for i := 0; i < b.N; i++ {
+		_, err := ek.DerivePath(path)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "keys/s")
+}
@@ -0,0 +1,231 @@
+package schnorrkel
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+const (
+	// ExtendedPublicKeyVersion is the version of an encoded extended public key, which makes
+	// the encoding start with "srpk"
+	ExtendedPublicKeyVersion uint32 = 0x5afcdbd0
+
+	// ExtendedSecretKeyVersion is the version of an encoded extended secret key, which makes
+	// the encoding start with "srsk"
+	ExtendedSecretKeyVersion uint32 = 0x0547eee8
+
+	// FingerprintLength is the length in bytes of a key fingerprint
+	FingerprintLength = 4
+
+	// MaxExtendedKeyDepth is the largest depth that can be encoded in an extended key
+	MaxExtendedKeyDepth = 255
+
+	extendedKeyHeaderSize = 4 + 1 + FingerprintLength + 1 + ChainCodeLength + ChainCodeLength
+	checksumSize          = 4
+)
+
+var (
+	// ErrInvalidExtendedKey is returned when decoding a malformed extended key
+	ErrInvalidExtendedKey = errors.New("invalid extended key")
+	// ErrExtendedKeyChecksum is returned when decoding an extended key with a wrong checksum
+	ErrExtendedKeyChecksum = errors.New("invalid extended key checksum")
+	// ErrExtendedKeyDepth is returned when encoding an extended key deeper than MaxExtendedKeyDepth
+	ErrExtendedKeyDepth = errors.New("extended key depth is too large to encode")
+)
+
+// ChildJunction identifies the junction an extended key was derived with from its parent, by
+// the Substrate chain code of the junction (see Junction.ChainCode) and whether it was hard
+type ChildJunction struct {
+	Hard bool
+	ID   [ChainCodeLength]byte
+}
+
+// Depth returns the number of derivations from the root key to the extended key
+func (ek *ExtendedKey) Depth() int {
+	return ek.depth
+}
+
+// ParentFingerprint returns the fingerprint of the key the extended key was derived from, or
+// zero for a root key
+func (ek *ExtendedKey) ParentFingerprint() [FingerprintLength]byte {
+	return ek.parentFingerprint
+}
+
+// Child returns the junction the extended key was derived with from its parent. It is the zero
+// value for a root key.
+func (ek *ExtendedKey) Child() ChildJunction {
+	return ek.child
+}
+
+// Fingerprint returns the first 4 bytes of the blake2b-256 hash of the extended key's public key
+func (ek *ExtendedKey) Fingerprint() ([FingerprintLength]byte, error) {
+	pub, err := ek.Public()
+	if err != nil {
+		return [FingerprintLength]byte{}, err
+	}
+
+	return publicKeyFingerprint(pub), nil
+}
+
+// Neuter returns the extended public key of the extended key, keeping its depth, parent
+// fingerprint and child junction, so that it can be given to a watch-only service
+func (ek *ExtendedKey) Neuter() (*ExtendedKey, error) {
+	pub, err := ek.Public()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExtendedKey{
+		key:               pub,
+		chaincode:         ek.chaincode,
+		path:              ek.path,
+		depth:             ek.depth,
+		parentFingerprint: ek.parentFingerprint,
+		child:             ek.child,
+	}, nil
+}
+
+// Encode returns the base58 encoding of the extended key, which is
+// version (4, BE) || depth (1) || parent fingerprint (4) || child junction flag (1: 0 for none,
+// 1 for soft, 2 for hard) || child junction ID (32) || chain code (32) || key || checksum (4).
+// The key is the 32-byte public key for ExtendedPublicKeyVersion, or the 32-byte secret key
+// followed by its 32-byte nonce for ExtendedSecretKeyVersion. The checksum is the first 4
+// bytes of the double SHA-256 of the preceding bytes, as in BIP-32.
+func (ek *ExtendedKey) Encode() (string, error) {
+	if ek.depth > MaxExtendedKeyDepth {
+		return "", ErrExtendedKeyDepth
+	}
+
+	var version uint32
+	var key []byte
+	switch k := ek.key.(type) {
+	case *PublicKey:
+		version = ExtendedPublicKeyVersion
+		enc := k.key.Encode([]byte{})
+		key = enc
+	case *SecretKey:
+		version = ExtendedSecretKeyVersion
+		key = append(k.key[:], k.nonce[:]...)
+	case *MiniSecretKey:
+		version = ExtendedSecretKeyVersion
+		sk := k.ExpandEd25519()
+		key = append(sk.key[:], sk.nonce[:]...)
+	default:
+		return "", errors.New("extended key is not a valid public or private key")
+	}
+
+	b := make([]byte, 0, extendedKeyHeaderSize+len(key)+checksumSize)
+	b = binary.BigEndian.AppendUint32(b, version)
+	b = append(b, byte(ek.depth))
+	b = append(b, ek.parentFingerprint[:]...)
+	switch {
+	case ek.depth == 0:
+		b = append(b, 0)
+	case ek.child.Hard:
+		b = append(b, 2)
+	default:
+		b = append(b, 1)
+	}
+	b = append(b, ek.child.ID[:]...)
+	b = append(b, ek.chaincode[:]...)
+	b = append(b, key...)
+	checksum := extendedKeyChecksum(b)
+	b = append(b, checksum[:]...)
+
+	return base58Encode(b), nil
+}
+
+// DecodeExtendedKey decodes an extended public or secret key encoded with ExtendedKey.Encode
+func DecodeExtendedKey(s string) (*ExtendedKey, error) {
+	b, err := base58Decode(s)
+	if err != nil {
+		return nil, ErrInvalidExtendedKey
+	}
+
+	if len(b) < extendedKeyHeaderSize+checksumSize {
+		return nil, ErrInvalidExtendedKey
+	}
+
+	payload, checksum := b[:len(b)-checksumSize], b[len(b)-checksumSize:]
+	expected := extendedKeyChecksum(payload)
+	if !bytes.Equal(checksum, expected[:]) {
+		return nil, ErrExtendedKeyChecksum
+	}
+
+	ek := &ExtendedKey{
+		depth: int(payload[4]),
+	}
+	copy(ek.parentFingerprint[:], payload[5:9])
+	flag := payload[9]
+	copy(ek.child.ID[:], payload[10:42])
+	copy(ek.chaincode[:], payload[42:74])
+	key := payload[74:]
+
+	switch {
+	case flag > 2, ek.depth == 0 && (flag != 0 || ek.parentFingerprint != [FingerprintLength]byte{} ||
+		ek.child.ID != [ChainCodeLength]byte{}), ek.depth > 0 && flag == 0:
+		return nil, ErrInvalidExtendedKey
+	}
+	ek.child.Hard = flag == 2
+
+	switch binary.BigEndian.Uint32(payload[:4]) {
+	case ExtendedPublicKeyVersion:
+		if len(key) != PublicKeySize {
+			return nil, ErrInvalidExtendedKey
+		}
+		pub, err := NewPublicKey([PublicKeySize]byte(key))
+		if err != nil {
+			return nil, err
+		}
+		ek.key = pub
+	case ExtendedSecretKeyVersion:
+		if len(key) != SecretKeySize+32 {
+			return nil, ErrInvalidExtendedKey
+		}
+		sk := NewSecretKey([SecretKeySize]byte(key[:SecretKeySize]), [32]byte(key[SecretKeySize:]))
+		if _, err := ScalarFromBytes(sk.key); err != nil {
+			return nil, err
+		}
+		ek.key = sk
+	default:
+		return nil, ErrInvalidExtendedKey
+	}
+
+	return ek, nil
+}
+
+// setChild records the parent fingerprint and child junction of an extended key derived from
+// parent with the junction
+func (ek *ExtendedKey) setChild(parent *ExtendedKey, j Junction) error {
+	fp, err := parent.Fingerprint()
+	if err != nil {
+		return err
+	}
+
+	ek.parentFingerprint = fp
+	ek.child = ChildJunction{
+		Hard: j.Hard,
+		ID:   j.ChainCode(),
+	}
+	return nil
+}
+
+func publicKeyFingerprint(pub *PublicKey) [FingerprintLength]byte {
+	enc := pub.key.Encode([]byte{})
+	h := blake2b.Sum256(enc)
+	fp := [FingerprintLength]byte{}
+	copy(fp[:], h[:FingerprintLength])
+	return fp
+}
+
+func extendedKeyChecksum(b []byte) [checksumSize]byte {
+	first := sha256.Sum256(b)
+	second := sha256.Sum256(first[:])
+	checksum := [checksumSize]byte{}
+	copy(checksum[:], second[:checksumSize])
+	return checksum
+}
@@ -0,0 +1,177 @@
+package schnorrkel
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gtank/merlin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtendedKey_Encode(t *testing.T) {
+	priv, _, err := GenerateKeypair()
+	require.NoError(t, err)
+	root := NewExtendedKey(priv, [ChainCodeLength]byte{9})
+
+	path, err := ParseDerivationPath("//polkadot//0/5")
+	require.NoError(t, err)
+	derived, err := root.DerivePath(path)
+	require.NoError(t, err)
+	require.Equal(t, 3, derived.Depth())
+	require.Equal(t, ChildJunction{Hard: false, ID: path[2].ChainCode()}, derived.Child())
+
+	parent, err := root.DerivePath(path[:2])
+	require.NoError(t, err)
+	fp, err := parent.Fingerprint()
+	require.NoError(t, err)
+	require.Equal(t, fp, derived.ParentFingerprint())
+
+	enc, err := derived.Encode()
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(enc, "srsk"), enc)
+
+	dec, err := DecodeExtendedKey(enc)
+	require.NoError(t, err)
+	require.Equal(t, derived.Depth(), dec.Depth())
+	require.Equal(t, derived.ParentFingerprint(), dec.ParentFingerprint())
+	require.Equal(t, derived.Child(), dec.Child())
+	require.Equal(t, derived.ChainCode(), dec.ChainCode())
+	decSecret, err := dec.Secret()
+	require.NoError(t, err)
+	derivedSecret, err := derived.Secret()
+	require.NoError(t, err)
+	require.Equal(t, derivedSecret.Encode(), decSecret.Encode())
+	require.Equal(t, derivedSecret.nonce, decSecret.nonce)
+
+	// a watch-only service derives the same soft children from the extended public key
+	xpub, err := derived.Neuter()
+	require.NoError(t, err)
+	pubEnc, err := xpub.Encode()
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(pubEnc, "srpk"), pubEnc)
+
+	watch, err := DecodeExtendedKey(pubEnc)
+	require.NoError(t, err)
+	require.Equal(t, 3, watch.Depth())
+	_, err = watch.Secret()
+	require.Error(t, err)
+
+	soft, err := ParseDerivationPath("/0/7")
+	require.NoError(t, err)
+	fromSecret, err := derived.DerivePath(soft)
+	require.NoError(t, err)
+	fromPublic, err := watch.DerivePath(soft)
+	require.NoError(t, err)
+	require.Equal(t, 5, fromPublic.Depth())
+
+	secretEnc, err := fromSecret.Neuter()
+	require.NoError(t, err)
+	a, err := secretEnc.Encode()
+	require.NoError(t, err)
+	b, err := fromPublic.Encode()
+	require.NoError(t, err)
+	require.Equal(t, a, b)
+
+	hard, err := ParseDerivationPath("//1")
+	require.NoError(t, err)
+	_, err = watch.DerivePath(hard)
+	require.ErrorIs(t, err, ErrDeriveHardKeyType)
+}
+
+func TestExtendedKey_DeriveKey(t *testing.T) {
+	msk, err := NewMiniSecretKeyFromRaw([32]byte{5})
+	require.NoError(t, err)
+	priv := msk.ExpandEd25519()
+	cc := [ChainCodeLength]byte{6}
+
+	for _, root := range []*ExtendedKey{NewExtendedKey(msk, cc), NewExtendedKey(priv, cc)} {
+		child, err := root.DeriveKey(merlin.NewTranscript("child"))
+		require.NoError(t, err)
+		require.Equal(t, 1, child.Depth())
+
+		fp, err := NewExtendedKey(priv, cc).Fingerprint()
+		require.NoError(t, err)
+		require.Equal(t, fp, child.ParentFingerprint())
+
+		// the child ID is the child's chain code
+		require.Equal(t, ChildJunction{ID: child.ChainCode()}, child.Child())
+
+		enc, err := child.Encode()
+		require.NoError(t, err)
+		dec, err := DecodeExtendedKey(enc)
+		require.NoError(t, err)
+		require.Equal(t, child.Child(), dec.Child())
+		require.Equal(t, child.ParentFingerprint(), dec.ParentFingerprint())
+
+		// a watch-only service derives the same extended public key
+		xpub, err := NewExtendedKey(priv, cc).Neuter()
+		require.NoError(t, err)
+		watch, err := xpub.DeriveKey(merlin.NewTranscript("child"))
+		require.NoError(t, err)
+		childPub, err := child.Neuter()
+		require.NoError(t, err)
+		a, err := childPub.Encode()
+		require.NoError(t, err)
+		b, err := watch.Encode()
+		require.NoError(t, err)
+		require.Equal(t, a, b)
+	}
+
+	_, err = NewExtendedKey(priv, cc).DeriveKey(nil)
+	require.Error(t, err)
+}
+
+func TestExtendedKey_EncodeRoot(t *testing.T) {
+	_, pub, err := GenerateKeypair()
+	require.NoError(t, err)
+
+	root := NewExtendedKey(pub, [ChainCodeLength]byte{1, 2, 3})
+	enc, err := root.Encode()
+	require.NoError(t, err)
+
+	dec, err := DecodeExtendedKey(enc)
+	require.NoError(t, err)
+	require.Equal(t, 0, dec.Depth())
+	require.Equal(t, [FingerprintLength]byte{}, dec.ParentFingerprint())
+	require.Equal(t, ChildJunction{}, dec.Child())
+	require.Equal(t, pub.Encode(), dec.Key().Encode())
+	require.Equal(t, root.ChainCode(), dec.ChainCode())
+}
+
+func TestDecodeExtendedKey_Invalid(t *testing.T) {
+	_, pub, err := GenerateKeypair()
+	require.NoError(t, err)
+	enc, err := NewExtendedKey(pub, [ChainCodeLength]byte{}).Encode()
+	require.NoError(t, err)
+
+	// change one character
+	c := byte('2')
+	if enc[20] == c {
+		c = '3'
+	}
+	_, err = DecodeExtendedKey(enc[:20] + string(c) + enc[21:])
+	require.ErrorIs(t, err, ErrExtendedKeyChecksum)
+
+	_, err = DecodeExtendedKey(enc[:len(enc)-1])
+	require.Error(t, err)
+
+	_, err = DecodeExtendedKey("srpk0OIl")
+	require.ErrorIs(t, err, ErrInvalidExtendedKey)
+}
+
+func TestBase58(t *testing.T) {
+	for _, c := range []struct {
+		in  []byte
+		out string
+	}{
+		{[]byte{}, ""},
+		{[]byte{0}, "1"},
+		{[]byte{0, 0, 1}, "112"},
+		{[]byte("hello world"), "StV1DL6CwTryKyV"},
+	} {
+		require.Equal(t, c.out, base58Encode(c.in))
+		dec, err := base58Decode(c.out)
+		require.NoError(t, err)
+		require.Equal(t, c.in, dec)
+	}
+}
@@ -0,0 +1,194 @@
+package schnorrkel
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// ErrInvalidDerivationPath is returned when parsing a malformed derivation path
+var ErrInvalidDerivationPath = errors.New("invalid derivation path")
+
+// Junction is a single hard or soft step of a DerivationPath
+type Junction struct {
+	Hard bool
+	Name string
+}
+
+// DerivationPath is a list of hard and soft junctions, written as "//hard/soft"
+type DerivationPath []Junction
+
+// ParseDerivationPath parses a path such as "//polkadot//0/1", where "//" starts a hard junction
+// and "/" a soft one. Junction names must be non-empty. The empty string is the empty path.
+func ParseDerivationPath(s string) (DerivationPath, error) {
+	path := DerivationPath{}
+	for len(s) > 0 {
+		if s[0] != '/' {
+			return nil, fmt.Errorf("%w: %q does not start with /", ErrInvalidDerivationPath, s)
+		}
+
+		j := Junction{}
+		s = s[1:]
+		if strings.HasPrefix(s, "/") {
+			j.Hard = true
+			s = s[1:]
+		}
+
+		end := strings.IndexByte(s, '/')
+		if end < 0 {
+			end = len(s)
+		}
+
+		if end == 0 {
+			return nil, fmt.Errorf("%w: empty junction", ErrInvalidDerivationPath)
+		}
+
+		j.Name = s[:end]
+		s = s[end:]
+		path = append(path, j)
+	}
+
+	return path, nil
+}
+
+// String formats the path as "//hard/soft"
+func (p DerivationPath) String() string {
+	sb := strings.Builder{}
+	for _, j := range p {
+		sb.WriteString(j.String())
+	}
+	return sb.String()
+}
+
+// String formats the junction as "//name" if it is hard or "/name" if it is soft
+func (j Junction) String() string {
+	if j.Hard {
+		return "//" + j.Name
+	}
+	return "/" + j.Name
+}
+
+// NewNumericJunction returns the junction for the integer n, such as "//0"
+func NewNumericJunction(n uint64, hard bool) Junction {
+	return Junction{
+		Hard: hard,
+		Name: strconv.FormatUint(n, 10),
+	}
+}
+
+// ChainCode returns the chain code Substrate derives for the junction. A name that parses as a
+// u64 is encoded as a SCALE little-endian u64, and any other name as a SCALE string with a
+// compact length prefix. Encodings of up to 32 bytes are zero-padded to 32 bytes, and longer
+// ones are hashed with blake2b-256.
+// see: https://github.com/paritytech/polkadot-sdk/blob/master/substrate/primitives/core/src/crypto.rs
+func (j Junction) ChainCode() [ChainCodeLength]byte {
+	var enc []byte
+	if n, ok := parseJunctionUint64(j.Name); ok {
+		enc = binary.LittleEndian.AppendUint64(nil, n)
+	} else {
+		enc = appendCompactUint64(nil, uint64(len(j.Name)))
+		enc = append(enc, j.Name...)
+	}
+
+	if len(enc) > ChainCodeLength {
+		return blake2b.Sum256(enc)
+	}
+
+	cc := [ChainCodeLength]byte{}
+	copy(cc[:], enc)
+	return cc
+}
+
+// DeriveKeySubstrate derives the key along the path as Substrate does for sr25519, starting from
+// a root key. It is DerivePath on the key with a zero chain code. Hard junctions require a
+// secret key.
+func DeriveKeySubstrate(key DerivableKey, path DerivationPath) (*ExtendedKey, error) {
+	return NewExtendedKey(key, [ChainCodeLength]byte{}).DerivePath(path)
+}
+
+// parseJunctionUint64 parses the name as a u64 the way Rust's str::parse does, which also
+// accepts a leading '+'
+func parseJunctionUint64(s string) (uint64, bool) {
+	s = strings.TrimPrefix(s, "+")
+	if s == "" || s[0] < '0' || s[0] > '9' {
+		return 0, false
+	}
+
+	n, err := strconv.ParseUint(s, 10, 64)
+	return n, err == nil
+}
+
+// appendCompactUint64 appends the SCALE compact encoding of n
+func appendCompactUint64(b []byte, n uint64) []byte {
+	switch {
+	case n < 1<<6:
+		return append(b, byte(n<<2))
+	case n < 1<<14:
+		return binary.LittleEndian.AppendUint16(b, uint16(n<<2|1))
+	case n < 1<<30:
+		return binary.LittleEndian.AppendUint32(b, uint32(n<<2|2))
+	default:
+		le := binary.LittleEndian.AppendUint64(nil, n)
+		size := 8
+		for le[size-1] == 0 {
+			size--
+		}
+		b = append(b, byte((size-4)<<2|3))
+		return append(b, le[:size]...)
+	}
+}
+
+// Path returns the path the ExtendedKey was derived with by DerivePath
+func (ek *ExtendedKey) Path() DerivationPath {
+	return ek.path
+}
+
+// DerivePath derives the extended key along the path as Substrate does for sr25519, with an
+// empty derivation index and the junction's ChainCode at every step, so a path gives the same
+// keys as subkey. As in Substrate, the chain code of this key and of every intermediate step is
+// not used. Hard junctions require a secret key.
+// The path of the resulting key is the path of this key followed by the given path, and its
+// depth, parent fingerprint and child junction are set for Encode.
+func (ek *ExtendedKey) DerivePath(path DerivationPath) (*ExtendedKey, error) {
+	key := ek.key
+	if msk, ok := key.(*MiniSecretKey); ok {
+		key = msk.ExpandEd25519()
+	}
+
+	derived := NewExtendedKey(key, ek.chaincode)
+	derived.depth = ek.depth
+	parent := derived
+	for i, j := range path {
+		var err error
+		parent = derived
+		if j.Hard {
+			if _, ok := derived.key.(*SecretKey); !ok {
+				return nil, fmt.Errorf("%w: hard junction %s at position %d of a public key derivation",
+					ErrDeriveHardKeyType, j, i)
+			}
+			derived, err = DeriveKeyHard(derived.key, []byte{}, j.ChainCode())
+		} else {
+			derived, err = DeriveKeySoft(derived.key, []byte{}, j.ChainCode())
+		}
+		if err != nil {
+			return nil, err
+		}
+		derived.depth = parent.depth + 1
+	}
+
+	if len(path) == 0 {
+		derived.parentFingerprint = ek.parentFingerprint
+		derived.child = ek.child
+	} else if err := derived.setChild(parent, path[len(path)-1]); err != nil {
+		return nil, err
+	}
+
+	derived.path = make(DerivationPath, 0, len(ek.path)+len(path))
+	derived.path = append(derived.path, ek.path...)
+	derived.path = append(derived.path, path...)
+	return derived, nil
+}
@@ -0,0 +1,191 @@
+package schnorrkel
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDerivationPath(t *testing.T) {
+	path, err := ParseDerivationPath("//polkadot//0/1/foo")
+	require.NoError(t, err)
+	require.Equal(t, DerivationPath{
+		{Hard: true, Name: "polkadot"},
+		{Hard: true, Name: "0"},
+		{Hard: false, Name: "1"},
+		{Hard: false, Name: "foo"},
+	}, path)
+	require.Equal(t, "//polkadot//0/1/foo", path.String())
+
+	path, err = ParseDerivationPath("")
+	require.NoError(t, err)
+	require.Empty(t, path)
+	require.Equal(t, "", path.String())
+
+	for _, s := range []string{"foo", "//", "/", "/a//", "///a", "/a/", "//a/b//"} {
+		_, err = ParseDerivationPath(s)
+		require.ErrorIs(t, err, ErrInvalidDerivationPath, s)
+	}
+}
+
+func TestExtendedKey_DerivePath(t *testing.T) {
+	priv, pub, err := GenerateKeypair()
+	require.NoError(t, err)
+
+	cc := [ChainCodeLength]byte{7}
+	path, err := ParseDerivationPath("//hard/soft")
+	require.NoError(t, err)
+
+	derived, err := NewExtendedKey(priv, cc).DerivePath(path)
+	require.NoError(t, err)
+	require.Equal(t, path, derived.Path())
+
+	// the same as deriving step by step with the Substrate chain codes of the junctions
+	hard, err := DeriveKeyHard(priv, []byte{}, path[0].ChainCode())
+	require.NoError(t, err)
+	expected, err := DeriveKeySoft(hard.Key(), []byte{}, path[1].ChainCode())
+	require.NoError(t, err)
+	require.Equal(t, expected.Key().Encode(), derived.Key().Encode())
+	require.Equal(t, expected.ChainCode(), derived.ChainCode())
+
+	// soft paths can be derived from the public key alone
+	soft, err := ParseDerivationPath("/0/1")
+	require.NoError(t, err)
+	fromSecret, err := derived.DerivePath(soft)
+	require.NoError(t, err)
+	require.Equal(t, "//hard/soft/0/1", fromSecret.Path().String())
+
+	derivedPub, err := derived.Public()
+	require.NoError(t, err)
+	fromPublic, err := NewExtendedKey(derivedPub, derived.ChainCode()).DerivePath(soft)
+	require.NoError(t, err)
+	require.Equal(t, "/0/1", fromPublic.Path().String())
+
+	secretPub, err := fromSecret.Public()
+	require.NoError(t, err)
+	require.Equal(t, secretPub.Encode(), fromPublic.Key().Encode())
+	require.Equal(t, fromSecret.ChainCode(), fromPublic.ChainCode())
+
+	// hard junctions need a secret key
+	_, err = NewExtendedKey(pub, cc).DerivePath(path)
+	require.ErrorIs(t, err, ErrDeriveHardKeyType)
+	require.Contains(t, err.Error(), "//hard")
+}
+
+func TestJunction_ChainCode(t *testing.T) {
+	for _, c := range []struct {
+		path string
+		cc   string
+	}{
+		// u64 0 as SCALE little-endian
+		{"//0", "0000000000000000000000000000000000000000000000000000000000000000"},
+		{"/1", "0100000000000000000000000000000000000000000000000000000000000000"},
+		{"//+7", "0700000000000000000000000000000000000000000000000000000000000000"},
+		{"//18446744073709551615", "ffffffffffffffff000000000000000000000000000000000000000000000000"},
+		// too large for a u64, so encoded as a string
+		{"//18446744073709551616", "5031383434363734343037333730393535313631360000000000000000000000"},
+		// compact length 5 followed by "Alice"
+		{"//Alice", "14416c6963650000000000000000000000000000000000000000000000000000"},
+		{"/foo", "0c666f6f00000000000000000000000000000000000000000000000000000000"},
+		// a 31-byte name fits in 32 bytes with its length prefix
+		{"//abcdefghijklmnopqrstuvwxyz01234", "7c6162636465666768696a6b6c6d6e6f707172737475767778797a3031323334"},
+		// 40-byte names are blake2b-256 hashed with their length prefix 0xa0
+		{"//abcdefghijklmnopqrstuvwxyz0123456789ABCD", "b94ca0552da1dffd6431adf495f6d46d873458e06d17ca8ca4a6a880bd42dd94"},
+		{"//aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", "02ac7775ba44703a066694f26190321465637e184af457eefabbdefa677c3d18"},
+		// two-byte compact length prefix 0x9101
+		{"/" + strings.Repeat("x", 100), "1b87f546690d933b7a06c89587c1eb6cf62c98b4caacb269f1cee2da6f133c1a"},
+	} {
+		path, err := ParseDerivationPath(c.path)
+		require.NoError(t, err)
+		require.Len(t, path, 1)
+
+		cc := path[0].ChainCode()
+		require.Equal(t, c.cc, hex.EncodeToString(cc[:]), c.path)
+	}
+
+	require.Equal(t, "//0", NewNumericJunction(0, true).String())
+	require.Equal(t, "/42", NewNumericJunction(42, false).String())
+}
+
+func TestDeriveKeySubstrate(t *testing.T) {
+	// the keypair of TestDeriveHard and TestDeriveSoft, whose chain codes are the Substrate
+	// encodings of //Alice and /foo
+	kp, err := hex.DecodeString("4c1250e05afcd79e74f6c035aee10248841090e009b6fd7ba6a98d5dc743250cafa4b32c608e3ee2ba624850b3f14c75841af84b16798bf1ee4a3875aa37a2cee661e416406384fe1ca091980958576d2bff7c461636e9f22c895f444905ea1f")
+	require.NoError(t, err)
+	priv := new(SecretKey)
+	require.NoError(t, priv.Decode([32]byte(kp[:32])))
+
+	for _, c := range []struct {
+		path   string
+		public string
+	}{
+		{"//Alice", "d8db757f04521a940f0237c8a1e44dfbe0b3e39af929eb2e9e257ba61b9a0a1a"},
+		{"/foo", "b21e5aabeeb35d6a1bf76226a6c65cd897016df09ef208243e59eed2401f5357"},
+	} {
+		path, err := ParseDerivationPath(c.path)
+		require.NoError(t, err)
+
+		derived, err := DeriveKeySubstrate(priv, path)
+		require.NoError(t, err)
+		require.Equal(t, path, derived.Path())
+
+		pub, err := derived.Public()
+		require.NoError(t, err)
+		enc := pub.Encode()
+		require.Equal(t, c.public, hex.EncodeToString(enc[:]))
+	}
+
+	pub, err := priv.Public()
+	require.NoError(t, err)
+	path, err := ParseDerivationPath("/foo//0")
+	require.NoError(t, err)
+	_, err = DeriveKeySubstrate(pub, path)
+	require.ErrorIs(t, err, ErrDeriveHardKeyType)
+}
+
+func TestExtendedKey_DerivePath_Subkey(t *testing.T) {
+	// the mini secret key of the mnemonic "crowd swamp sniff machine grid pretty client emotion
+	// banana cricket flush soap" and the addresses subkey derives from it
+	msk, err := NewMiniSecretKeyFromHex("0x18446f2d685492c3086391aabe8f5e235c3c2e02521985650f0c97052237e717")
+	require.NoError(t, err)
+
+	for _, c := range []struct {
+		path    string
+		address string
+	}{
+		{"//foo", "5CAvHXaqNRwbbL4B3MoQJdam8JmotCGAF8kTpgWhR9ahhJYS"},
+		{"/foo", "5CyjA4yQrQtJBs7jC4D6S672y3Ez4Shd3se6VXB4JBkdGwUZ"},
+		{"//foo//42", "5H68C9rPXxtbsAZMznJaLJWfg1GXDuf3yAgjZoMYcfGxZ6Db"},
+		{"//foo/bar", "5CM1gMJkyRoE7txkdHv31y6H4yPMKCALSDpaeaE8BpDVwrht"},
+		{"/foo//bar", "5HE5Y6MDZvy9QJsmgjrnJHiSqsYRTrfBLrzLvHQC3f9PM6TR"},
+		{"//foo/bar//42/69", "5ERv3mLP7CX1CViNc6NUQaePBJMkf6BELffpMfXjXjj28SNo"},
+	} {
+		path, err := ParseDerivationPath(c.path)
+		require.NoError(t, err)
+
+		// the chain code of the root key is not used
+		for _, cc := range [][ChainCodeLength]byte{{}, {1}} {
+			derived, err := NewExtendedKey(msk, cc).DerivePath(path)
+			require.NoError(t, err)
+
+			pub, err := derived.Public()
+			require.NoError(t, err)
+			address, err := pub.SS58Address(SubstrateSS58Prefix)
+			require.NoError(t, err)
+			require.Equal(t, c.address, address, c.path)
+		}
+
+		// deriving the path in two parts gives the same key
+		first, err := NewExtendedKey(msk, [ChainCodeLength]byte{}).DerivePath(path[:1])
+		require.NoError(t, err)
+		derived, err := first.DerivePath(path[1:])
+		require.NoError(t, err)
+		pub, err := derived.Public()
+		require.NoError(t, err)
+		address, err := pub.SS58Address(SubstrateSS58Prefix)
+		require.NoError(t, err)
+		require.Equal(t, c.address, address, c.path)
+	}
+}
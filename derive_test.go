@@ -4,6 +4,7 @@ import (
 	"encoding/hex"
 	"testing"
 
+	"github.com/gtank/merlin"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/blake2b"
 )
@@ -128,3 +129,48 @@ func deriveCommon(t *testing.T, vec commonVectors) {
 	resultPubBytes := resultPub.Encode()
 	require.Equal(t, expectedPub, resultPubBytes[:])
 }
+
+func TestDeriveKeySimple_DeterministicNonce(t *testing.T) {
+	priv, _, err := GenerateKeypair()
+	require.NoError(t, err)
+
+	cc := [ChainCodeLength]byte{1}
+	d1, err := DeriveKeySimple(priv, []byte("soft"), cc)
+	require.NoError(t, err)
+	d2, err := DeriveKeySimple(priv, []byte("soft"), cc)
+	require.NoError(t, err)
+
+	sk1, err := d1.Secret()
+	require.NoError(t, err)
+	sk2, err := d2.Secret()
+	require.NoError(t, err)
+	require.Equal(t, sk1.key, sk2.key)
+	require.Equal(t, sk1.nonce, sk2.nonce)
+	require.NotEqual(t, priv.nonce, sk1.nonce)
+	require.NotEqual(t, sk1.key[:], sk1.nonce[:])
+
+	// the nonce depends on the path
+	d3, err := DeriveKeySimple(priv, []byte("other"), cc)
+	require.NoError(t, err)
+	sk3, err := d3.Secret()
+	require.NoError(t, err)
+	require.NotEqual(t, sk1.nonce, sk3.nonce)
+
+	// and on the parent nonce, but the derived key does not
+	other := NewSecretKey(priv.key, [32]byte{1})
+	d4, err := DeriveKeySimple(other, []byte("soft"), cc)
+	require.NoError(t, err)
+	sk4, err := d4.Secret()
+	require.NoError(t, err)
+	require.Equal(t, sk1.key, sk4.key)
+	require.NotEqual(t, sk1.nonce, sk4.nonce)
+
+	// the caller's transcript does not depend on the secret key's nonce
+	t1 := merlin.NewTranscript("SchnorrRistrettoHDKD")
+	_, err = priv.DeriveKey(t1, cc)
+	require.NoError(t, err)
+	t4 := merlin.NewTranscript("SchnorrRistrettoHDKD")
+	_, err = other.DeriveKey(t4, cc)
+	require.NoError(t, err)
+	require.Equal(t, t1.ExtractBytes([]byte("test"), 32), t4.ExtractBytes([]byte("test"), 32))
+}
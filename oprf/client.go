@@ -0,0 +1,162 @@
+package oprf
+
+import (
+	"errors"
+
+	"github.com/ChainSafe/go-schnorrkel"
+	r255 "github.com/gtank/ristretto255"
+)
+
+// Client blinds inputs for a server to evaluate, and finalizes the server's evaluations into
+// PRF outputs
+type Client struct {
+	suite *suite
+	pub   *r255.Element
+}
+
+// FinalizeData is the client state kept between Blind and Finalize
+type FinalizeData struct {
+	inputs  [][]byte
+	blinds  []*r255.Scalar
+	blinded []*r255.Element
+}
+
+// NewClient creates a client for the mode. The server's public key is required in ModeVOPRF
+// and ModePOPRF, and ignored in ModeOPRF.
+func NewClient(mode Mode, pub *schnorrkel.PublicKey) (*Client, error) {
+	suite, err := newSuite(mode)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Client{
+		suite: suite,
+	}
+	if mode == ModeOPRF {
+		return c, nil
+	}
+
+	if pub == nil {
+		return nil, ErrMissingPublicKey
+	}
+
+	c.pub, err = decodeElement(pub.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Mode returns the mode of the client
+func (c *Client) Mode() Mode {
+	return c.suite.mode
+}
+
+// Blind hashes each input to an element and blinds it with a random scalar. The blinded
+// elements are sent to the server, and the FinalizeData kept for Finalize.
+func (c *Client) Blind(inputs [][]byte) (*FinalizeData, [][ElementSize]byte, error) {
+	blinds := make([]*r255.Scalar, len(inputs))
+	for i := range blinds {
+		var err error
+		blinds[i], err = schnorrkel.NewRandomScalar()
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return c.blind(inputs, blinds)
+}
+
+func (c *Client) blind(inputs [][]byte, blinds []*r255.Scalar) (*FinalizeData, [][ElementSize]byte, error) {
+	fd := &FinalizeData{
+		inputs:  make([][]byte, len(inputs)),
+		blinds:  blinds,
+		blinded: make([]*r255.Element, len(inputs)),
+	}
+	blinded := make([][ElementSize]byte, len(inputs))
+	for i, input := range inputs {
+		if len(input) > 0xffff {
+			return nil, nil, ErrInputTooLong
+		}
+
+		element := c.suite.hashToGroup(input)
+		if element.Equal(r255.NewElement()) == 1 {
+			return nil, nil, ErrInvalidInput
+		}
+
+		fd.inputs[i] = append([]byte{}, input...)
+		fd.blinded[i] = element.ScalarMult(blinds[i], element)
+		blinded[i] = encodeElement(fd.blinded[i])
+	}
+
+	return fd, blinded, nil
+}
+
+// Finalize verifies the server's evaluation in ModeVOPRF and ModePOPRF, unblinds it and returns
+// the PRF output of each input. The info is only used in ModePOPRF, and must be the same info
+// the server evaluated with.
+func (c *Client) Finalize(fd *FinalizeData, ev *Evaluation, info []byte) ([][]byte, error) {
+	if fd == nil {
+		return nil, errors.New("finalize data provided is nil")
+	}
+
+	if ev == nil {
+		return nil, errors.New("evaluation provided is nil")
+	}
+
+	if len(ev.Elements) != len(fd.inputs) {
+		return nil, ErrMismatchedLength
+	}
+
+	evaluated := make([]*r255.Element, len(ev.Elements))
+	for i, e := range ev.Elements {
+		var err error
+		evaluated[i], err = decodeElement(e)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch c.suite.mode {
+	case ModeVOPRF:
+		if ev.Proof == nil {
+			return nil, ErrMissingProof
+		}
+		ok, err := c.suite.verifyProof(r255.NewElement().Base(), c.pub, fd.blinded, evaluated, ev.Proof)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, ErrVerify
+		}
+	case ModePOPRF:
+		if ev.Proof == nil {
+			return nil, ErrMissingProof
+		}
+		m, err := c.suite.infoScalar(info)
+		if err != nil {
+			return nil, err
+		}
+		tweakedKey := r255.NewElement().ScalarBaseMult(m)
+		tweakedKey.Add(tweakedKey, c.pub)
+		if tweakedKey.Equal(r255.NewElement()) == 1 {
+			return nil, ErrInverse
+		}
+		ok, err := c.suite.verifyProof(r255.NewElement().Base(), tweakedKey, evaluated, fd.blinded, ev.Proof)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, ErrVerify
+		}
+	}
+
+	outputs := make([][]byte, len(evaluated))
+	for i, e := range evaluated {
+		inv := r255.NewScalar().Invert(fd.blinds[i])
+		outputs[i] = c.suite.finalizeHash(fd.inputs[i], info, e.ScalarMult(inv, e))
+	}
+
+	return outputs, nil
+}
@@ -0,0 +1,310 @@
+// Package oprf implements the OPRF, VOPRF and POPRF protocols of RFC 9497 with the
+// ristretto255-SHA512 ciphersuite, using sr25519 secret keys as server keys.
+// see: https://www.rfc-editor.org/rfc/rfc9497.html
+package oprf
+
+import (
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+
+	"github.com/ChainSafe/go-schnorrkel"
+	r255 "github.com/gtank/ristretto255"
+)
+
+// Mode is an RFC 9497 protocol variant
+type Mode byte
+
+const (
+	// ModeOPRF is the base oblivious PRF
+	ModeOPRF Mode = 0x00
+	// ModeVOPRF is the verifiable OPRF, in which the server proves its evaluation against its public key
+	ModeVOPRF Mode = 0x01
+	// ModePOPRF is the partially-oblivious verifiable OPRF, which takes public info from both parties
+	ModePOPRF Mode = 0x02
+)
+
+const (
+	// Identifier is the RFC 9497 ciphersuite identifier
+	Identifier = "ristretto255-SHA512"
+
+	// OutputSize is the length in bytes of a PRF output
+	OutputSize = sha512.Size
+
+	// ElementSize is the length in bytes of an encoded element
+	ElementSize = 32
+
+	// ProofSize is the length in bytes of an encoded proof
+	ProofSize = 64
+)
+
+var (
+	ErrInvalidMode      = errors.New("invalid OPRF mode")
+	ErrInvalidInput     = errors.New("input hashes to the identity element")
+	ErrInvalidElement   = errors.New("invalid element encoding")
+	ErrInverse          = errors.New("info makes the tweaked key zero")
+	ErrVerify           = errors.New("evaluation proof is invalid")
+	ErrDeriveKeyPair    = errors.New("could not derive a non-zero key pair")
+	ErrMismatchedLength = errors.New("number of elements does not match number of inputs")
+	ErrMissingPublicKey = errors.New("server public key is required in VOPRF and POPRF modes")
+	ErrMissingProof     = errors.New("evaluation proof is required in VOPRF and POPRF modes")
+	ErrInputTooLong     = errors.New("input or info is longer than 65535 bytes")
+	ErrBatchTooLarge    = errors.New("batch has more than 65535 elements")
+)
+
+// Proof is a DLEQ proof that a batch of elements was evaluated with the server key
+type Proof struct {
+	c *r255.Scalar
+	s *r255.Scalar
+}
+
+// Evaluation is the server's response to a batch of blinded elements
+type Evaluation struct {
+	Elements [][ElementSize]byte
+	Proof    *Proof // nil in ModeOPRF
+}
+
+// Encode returns the 64-byte encoding of the proof, c followed by s
+func (p *Proof) Encode() [ProofSize]byte {
+	enc := [ProofSize]byte{}
+	copy(enc[:32], p.c.Encode([]byte{}))
+	copy(enc[32:], p.s.Encode([]byte{}))
+	return enc
+}
+
+// Decode sets the Proof to the decoded input
+func (p *Proof) Decode(in [ProofSize]byte) error {
+	c := r255.NewScalar()
+	err := c.Decode(in[:32])
+	if err != nil {
+		return err
+	}
+
+	s := r255.NewScalar()
+	err = s.Decode(in[32:])
+	if err != nil {
+		return err
+	}
+
+	p.c = c
+	p.s = s
+	return nil
+}
+
+// DeriveKeyPair deterministically derives a server key pair from the seed and info, as in
+// RFC 9497 section 3.2.1. The sr25519 nonce of the secret key, which RFC 9497 does not use,
+// is derived from the same input.
+func DeriveKeyPair(mode Mode, seed [32]byte, info []byte) (*schnorrkel.SecretKey, *schnorrkel.PublicKey, error) {
+	suite, err := newSuite(mode)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(info) > 0xffff {
+		return nil, nil, ErrInputTooLong
+	}
+
+	deriveInput := append(seed[:], lengthPrefixed(info)...)
+	dst := append([]byte("DeriveKeyPair"), suite.context...)
+
+	sk := r255.NewScalar().Zero()
+	for counter := 0; sk.Equal(r255.NewScalar().Zero()) == 1; counter++ {
+		if counter > 255 {
+			return nil, nil, ErrDeriveKeyPair
+		}
+		sk = hashToScalar(append(deriveInput, byte(counter)), dst)
+	}
+
+	key := [schnorrkel.SecretKeySize]byte{}
+	copy(key[:], sk.Encode([]byte{}))
+	nonce := [32]byte{}
+	copy(nonce[:], expandMessageXMD(deriveInput, append([]byte("DeriveKeyPairNonce"), suite.context...), 32))
+
+	secretKey := schnorrkel.NewSecretKey(key, nonce)
+	pub, err := secretKey.Public()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return secretKey, pub, nil
+}
+
+// suite holds the mode-specific context string of the ciphersuite
+type suite struct {
+	mode    Mode
+	context []byte
+}
+
+func newSuite(mode Mode) (*suite, error) {
+	if mode > ModePOPRF {
+		return nil, ErrInvalidMode
+	}
+
+	// contextString = "OPRFV1-" || I2OSP(mode, 1) || "-" || identifier
+	context := append([]byte("OPRFV1-"), byte(mode))
+	context = append(context, '-')
+	context = append(context, Identifier...)
+	return &suite{
+		mode:    mode,
+		context: context,
+	}, nil
+}
+
+func (s *suite) hashToGroup(input []byte) *r255.Element {
+	dst := append([]byte("HashToGroup-"), s.context...)
+	return r255.NewElement().FromUniformBytes(expandMessageXMD(input, dst, 64))
+}
+
+func (s *suite) hashToScalar(input []byte) *r255.Scalar {
+	return hashToScalar(input, append([]byte("HashToScalar-"), s.context...))
+}
+
+// infoScalar returns HashToScalar("Info" || I2OSP(len(info), 2) || info), by which the
+// server key is tweaked in ModePOPRF
+func (s *suite) infoScalar(info []byte) (*r255.Scalar, error) {
+	if len(info) > 0xffff {
+		return nil, ErrInputTooLong
+	}
+
+	framed := append([]byte("Info"), lengthPrefixed(info)...)
+	return s.hashToScalar(framed), nil
+}
+
+// finalizeHash returns the PRF output for the input, info and unblinded element
+func (s *suite) finalizeHash(input, info []byte, element *r255.Element) []byte {
+	h := sha512.New()
+	h.Write(lengthPrefixed(input))
+	if s.mode == ModePOPRF {
+		h.Write(lengthPrefixed(info))
+	}
+	h.Write(lengthPrefixed(element.Encode([]byte{})))
+	h.Write([]byte("Finalize"))
+	return h.Sum(nil)
+}
+
+// computeComposites returns the composite elements M = sum(d_i*C_i) and Z = sum(d_i*D_i) for
+// the batch. If k is not nil Z is computed as k*M instead. The index of each element is encoded
+// in two bytes, so batches are limited to 65535 elements.
+func (s *suite) computeComposites(k *r255.Scalar, b *r255.Element, cs, ds []*r255.Element) (*r255.Element, *r255.Element, error) {
+	if len(cs) > 0xffff {
+		return nil, nil, ErrBatchTooLarge
+	}
+
+	seedDST := append([]byte("Seed-"), s.context...)
+	seedTranscript := append(lengthPrefixed(b.Encode([]byte{})), lengthPrefixed(seedDST)...)
+	seed := sha512.Sum512(seedTranscript)
+
+	weights := make([]*r255.Scalar, len(cs))
+	for i := range cs {
+		transcript := lengthPrefixed(seed[:])
+		transcript = binary.BigEndian.AppendUint16(transcript, uint16(i))
+		transcript = append(transcript, lengthPrefixed(cs[i].Encode([]byte{}))...)
+		transcript = append(transcript, lengthPrefixed(ds[i].Encode([]byte{}))...)
+		transcript = append(transcript, "Composite"...)
+		weights[i] = s.hashToScalar(transcript)
+	}
+
+	m := r255.NewElement().VarTimeMultiScalarMult(weights, cs)
+	if k != nil {
+		return m, r255.NewElement().ScalarMult(k, m), nil
+	}
+	return m, r255.NewElement().VarTimeMultiScalarMult(weights, ds), nil
+}
+
+func (s *suite) challenge(b, m, z, t2, t3 *r255.Element) *r255.Scalar {
+	transcript := []byte{}
+	for _, e := range []*r255.Element{b, m, z, t2, t3} {
+		transcript = append(transcript, lengthPrefixed(e.Encode([]byte{}))...)
+	}
+	transcript = append(transcript, "Challenge"...)
+	return s.hashToScalar(transcript)
+}
+
+// generateProof proves that k*A = B and k*C_i = D_i for every i, using the nonce r
+func (s *suite) generateProof(k *r255.Scalar, a, b *r255.Element, cs, ds []*r255.Element, r *r255.Scalar) (*Proof, error) {
+	m, z, err := s.computeComposites(k, b, cs, ds)
+	if err != nil {
+		return nil, err
+	}
+
+	t2 := r255.NewElement().ScalarMult(r, a)
+	t3 := r255.NewElement().ScalarMult(r, m)
+
+	c := s.challenge(b, m, z, t2, t3)
+	return &Proof{
+		c: c,
+		s: r255.NewScalar().Subtract(r, r255.NewScalar().Multiply(c, k)),
+	}, nil
+}
+
+func (s *suite) verifyProof(a, b *r255.Element, cs, ds []*r255.Element, proof *Proof) (bool, error) {
+	if proof.c == nil || proof.s == nil {
+		return false, nil
+	}
+
+	m, z, err := s.computeComposites(nil, b, cs, ds)
+	if err != nil {
+		return false, err
+	}
+
+	t2 := r255.NewElement().VarTimeMultiScalarMult([]*r255.Scalar{proof.s, proof.c}, []*r255.Element{a, b})
+	t3 := r255.NewElement().VarTimeMultiScalarMult([]*r255.Scalar{proof.s, proof.c}, []*r255.Element{m, z})
+	return s.challenge(b, m, z, t2, t3).Equal(proof.c) == 1, nil
+}
+
+func hashToScalar(input, dst []byte) *r255.Scalar {
+	return r255.NewScalar().FromUniformBytes(expandMessageXMD(input, dst, 64))
+}
+
+// expandMessageXMD is expand_message_xmd from RFC 9380 section 5.3.1 with SHA-512.
+// The DSTs used by this package are all shorter than 256 bytes.
+func expandMessageXMD(msg, dst []byte, length int) []byte {
+	dstPrime := append(append([]byte{}, dst...), byte(len(dst)))
+	ell := (length + sha512.Size - 1) / sha512.Size
+
+	h := sha512.New()
+	h.Write(make([]byte, sha512.BlockSize))
+	h.Write(msg)
+	h.Write([]byte{byte(length >> 8), byte(length), 0})
+	h.Write(dstPrime)
+	b0 := h.Sum(nil)
+
+	out := make([]byte, 0, ell*sha512.Size)
+	bi := make([]byte, sha512.Size)
+	for i := 1; i <= ell; i++ {
+		for j := range bi {
+			bi[j] ^= b0[j]
+		}
+		h.Reset()
+		h.Write(bi)
+		h.Write([]byte{byte(i)})
+		h.Write(dstPrime)
+		bi = h.Sum(nil)
+		out = append(out, bi...)
+	}
+
+	return out[:length]
+}
+
+// lengthPrefixed returns I2OSP(len(b), 2) || b
+func lengthPrefixed(b []byte) []byte {
+	return append(binary.BigEndian.AppendUint16(nil, uint16(len(b))), b...)
+}
+
+// decodeElement decodes an element, rejecting the identity as RFC 9497 requires
+func decodeElement(in [ElementSize]byte) (*r255.Element, error) {
+	e := r255.NewElement()
+	if err := e.Decode(in[:]); err != nil {
+		return nil, ErrInvalidElement
+	}
+	if e.Equal(r255.NewElement()) == 1 {
+		return nil, ErrInvalidElement
+	}
+	return e, nil
+}
+
+func encodeElement(e *r255.Element) [ElementSize]byte {
+	enc := [ElementSize]byte{}
+	copy(enc[:], e.Encode([]byte{}))
+	return enc
+}
@@ -0,0 +1,171 @@
+package oprf
+
+import (
+	"errors"
+
+	"github.com/ChainSafe/go-schnorrkel"
+	r255 "github.com/gtank/ristretto255"
+)
+
+// Server evaluates blinded elements with an sr25519 secret key
+type Server struct {
+	suite *suite
+	key   *r255.Scalar
+	pub   *schnorrkel.PublicKey
+}
+
+// NewServer creates a server for the mode with the given secret key
+func NewServer(mode Mode, secretKey *schnorrkel.SecretKey) (*Server, error) {
+	suite, err := newSuite(mode)
+	if err != nil {
+		return nil, err
+	}
+
+	if secretKey == nil {
+		return nil, errors.New("secret key provided is nil")
+	}
+
+	key, err := schnorrkel.ScalarFromBytes(secretKey.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	if key.Equal(r255.NewScalar().Zero()) == 1 {
+		return nil, errors.New("secret key is zero")
+	}
+
+	pub, err := secretKey.Public()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Server{
+		suite: suite,
+		key:   key,
+		pub:   pub,
+	}, nil
+}
+
+// Mode returns the mode of the server
+func (s *Server) Mode() Mode {
+	return s.suite.mode
+}
+
+// PublicKey returns the server's public key, which clients need in ModeVOPRF and ModePOPRF
+func (s *Server) PublicKey() *schnorrkel.PublicKey {
+	return s.pub
+}
+
+// BlindEvaluate evaluates a batch of blinded elements from a client. In ModeVOPRF and ModePOPRF
+// the evaluation includes a single proof for the whole batch. The info is only used in ModePOPRF.
+func (s *Server) BlindEvaluate(blinded [][ElementSize]byte, info []byte) (*Evaluation, error) {
+	r, err := schnorrkel.NewRandomScalar()
+	if err != nil {
+		return nil, err
+	}
+
+	return s.blindEvaluate(blinded, info, r)
+}
+
+func (s *Server) blindEvaluate(blinded [][ElementSize]byte, info []byte, r *r255.Scalar) (*Evaluation, error) {
+	ds := make([]*r255.Element, len(blinded))
+	for i, b := range blinded {
+		var err error
+		ds[i], err = decodeElement(b)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	k, err := s.evaluationKey(info)
+	if err != nil {
+		return nil, err
+	}
+
+	evaluated := make([]*r255.Element, len(ds))
+	ev := &Evaluation{
+		Elements: make([][ElementSize]byte, len(ds)),
+	}
+	for i, d := range ds {
+		evaluated[i] = r255.NewElement().ScalarMult(k, d)
+		ev.Elements[i] = encodeElement(evaluated[i])
+	}
+
+	switch s.suite.mode {
+	case ModeVOPRF:
+		ev.Proof, err = s.suite.generateProof(s.key, r255.NewElement().Base(), s.pubElement(), ds, evaluated, r)
+		if err != nil {
+			return nil, err
+		}
+	case ModePOPRF:
+		t, err := s.tweak(info)
+		if err != nil {
+			return nil, err
+		}
+		tweakedKey := r255.NewElement().ScalarBaseMult(t)
+		ev.Proof, err = s.suite.generateProof(t, r255.NewElement().Base(), tweakedKey, evaluated, ds, r)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return ev, nil
+}
+
+// Evaluate computes the PRF output for the input directly, without blinding. The info is only
+// used in ModePOPRF.
+func (s *Server) Evaluate(input, info []byte) ([]byte, error) {
+	if len(input) > 0xffff {
+		return nil, ErrInputTooLong
+	}
+
+	element := s.suite.hashToGroup(input)
+	if element.Equal(r255.NewElement()) == 1 {
+		return nil, ErrInvalidInput
+	}
+
+	k, err := s.evaluationKey(info)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.suite.finalizeHash(input, info, element.ScalarMult(k, element)), nil
+}
+
+// evaluationKey returns the scalar the server multiplies elements by: the secret key, or in
+// ModePOPRF the inverse of the secret key tweaked by the info
+func (s *Server) evaluationKey(info []byte) (*r255.Scalar, error) {
+	if s.suite.mode != ModePOPRF {
+		return s.key, nil
+	}
+
+	t, err := s.tweak(info)
+	if err != nil {
+		return nil, err
+	}
+
+	return r255.NewScalar().Invert(t), nil
+}
+
+// tweak returns the ModePOPRF tweaked key t = sk + infoScalar(info)
+func (s *Server) tweak(info []byte) (*r255.Scalar, error) {
+	m, err := s.suite.infoScalar(info)
+	if err != nil {
+		return nil, err
+	}
+
+	t := r255.NewScalar().Add(s.key, m)
+	if t.Equal(r255.NewScalar().Zero()) == 1 {
+		return nil, ErrInverse
+	}
+
+	return t, nil
+}
+
+func (s *Server) pubElement() *r255.Element {
+	enc := s.pub.Encode()
+	e := r255.NewElement()
+	// the public key was computed from the secret key, so it always decodes
+	_ = e.Decode(enc[:])
+	return e
+}
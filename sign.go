@@ -134,18 +134,23 @@ func (publicKey *PublicKey) Verify(s *Signature, t *merlin.Transcript) (bool, er
 		return false, ErrPublicKeyAtInfinity
 	}
 
-	t.AppendMessage([]byte("proto-name"), []byte("Schnorr-sig"))
-	pubc := publicKey.Encode()
-	t.AppendMessage([]byte("sign:pk"), pubc[:])
-	t.AppendMessage([]byte("sign:R"), s.r.Encode([]byte{}))
-
-	kb := t.ExtractBytes([]byte("sign:c"), 64)
-	k := r255.NewScalar()
-	k.FromUniformBytes(kb)
+	k := signatureChallenge(t, s, publicKey.Encode())
+	return publicKey.verifyWithChallenge(s, k), nil
+}
 
+// verifyWithChallenge checks the signature against an already computed challenge scalar k.
+func (publicKey *PublicKey) verifyWithChallenge(s *Signature, k *r255.Scalar) bool {
 	Rp := r255.NewElement().VarTimeDoubleScalarBaseMult(k, r255.NewElement().Negate(publicKey.key), s.s)
+	return Rp.Equal(s.r) == 1
+}
 
-	return Rp.Equal(s.r) == 1, nil
+// signatureChallenge commits the public key and signature R to the transcript and returns
+// the challenge scalar k = H(R || P || m).
+func signatureChallenge(t *merlin.Transcript, s *Signature, pubc [PublicKeySize]byte) *r255.Scalar {
+	t.AppendMessage([]byte("proto-name"), []byte("Schnorr-sig"))
+	t.AppendMessage([]byte("sign:pk"), pubc[:])
+	t.AppendMessage([]byte("sign:R"), s.r.Encode([]byte{}))
+	return challengeScalar(t, []byte("sign:c"))
 }
 
 // Verify verifies a schnorr signature with format: (R, s) where y is the public key
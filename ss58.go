@@ -0,0 +1,52 @@
+package schnorrkel
+
+import (
+	"errors"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+const (
+	// SubstrateSS58Prefix is the generic Substrate SS58 address prefix
+	SubstrateSS58Prefix uint16 = 42
+
+	// MaxSS58Prefix is the largest SS58 address prefix
+	MaxSS58Prefix uint16 = 16383
+
+	ss58ChecksumSize = 2
+)
+
+// ErrInvalidSS58Prefix is returned for an SS58 address prefix larger than MaxSS58Prefix
+var ErrInvalidSS58Prefix = errors.New("invalid SS58 address prefix")
+
+// SS58Address returns the SS58 address of the public key for the network prefix, such as
+// SubstrateSS58Prefix, 0 for Polkadot or 2 for Kusama
+// see: https://docs.substrate.io/reference/address-formats/
+func (publicKey *PublicKey) SS58Address(prefix uint16) (string, error) {
+	if prefix > MaxSS58Prefix {
+		return "", ErrInvalidSS58Prefix
+	}
+
+	var b []byte
+	if prefix < 64 {
+		b = []byte{byte(prefix)}
+	} else {
+		b = []byte{
+			byte((prefix&0xfc)>>2) | 0x40,
+			byte(prefix>>8) | byte(prefix&0x03)<<6,
+		}
+	}
+
+	pub := publicKey.key.Encode([]byte{})
+	b = append(b, pub...)
+
+	h, err := blake2b.New512(nil)
+	if err != nil {
+		return "", err
+	}
+	h.Write([]byte("SS58PRE"))
+	h.Write(b)
+	checksum := h.Sum(nil)
+
+	return base58Encode(append(b, checksum[:ss58ChecksumSize]...)), nil
+}
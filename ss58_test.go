@@ -0,0 +1,31 @@
+package schnorrkel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublicKey_SS58Address(t *testing.T) {
+	// the public key of //Alice
+	pub, err := NewPublicKeyFromHex("0xd43593c715fdd31c61141abd04a99fd6822c8558854ccde39a5684e7a56da27d")
+	require.NoError(t, err)
+
+	for _, c := range []struct {
+		prefix  uint16
+		address string
+	}{
+		{SubstrateSS58Prefix, "5GrwvaEF5zXb26Fz9rcQpDWS57CtERHpNehXCPcNoHGKutQY"},
+		{0, "15oF4uVJwmo4TdGW7VfQxNLavjCXviqxT9S1MgbjMNHr6Sp5"},
+		{2, "HNZata7iMYWmk5RvZRTiAsSDhV8366zq2YGb3tLH5Upf74F"},
+		{255, "yGHXkYLYqxijLKKfd9Q2CB9shRVu8rPNBS53wvwGTutYg4zTg"},
+		{MaxSS58Prefix, "yNa8JpqfFB3q8A29rCwSgxvdU94ufJw2yKKxDgznS5m1PoFvn"},
+	} {
+		address, err := pub.SS58Address(c.prefix)
+		require.NoError(t, err)
+		require.Equal(t, c.address, address)
+	}
+
+	_, err = pub.SS58Address(MaxSS58Prefix + 1)
+	require.ErrorIs(t, err, ErrInvalidSS58Prefix)
+}
@@ -14,6 +14,9 @@ var kusamaVRF = true
 
 const VRFLabel = "VRF"
 
+// VrfSignatureSize is the length in bytes of an encoded VrfSignature
+const VrfSignatureSize = 96
+
 type VrfInOut struct {
 	input  *r255.Element
 	output *r255.Element
@@ -28,6 +31,21 @@ type VrfProof struct {
 	s *r255.Scalar
 }
 
+// VrfSignature is a VRF output (pre-output) together with its proof, as passed around by Substrate
+type VrfSignature struct {
+	output *VrfOutput
+	proof  *VrfProof
+}
+
+// VrfProofBatchable is the longer (R, Hr, s) form of a VrfProof, which can be
+// verified in a batch with other proofs
+// see: https://github.com/w3f/schnorrkel/blob/798ab3e0813aa478b520c5cf6dc6e02fd4e07f0a/src/vrf.rs
+type VrfProofBatchable struct {
+	r  *r255.Element
+	hr *r255.Element
+	s  *r255.Scalar
+}
+
 // SetKusama sets the VRF kusama option. Defaults to true.
 func SetKusamaVRF(k bool) {
 	kusamaVRF = k
@@ -148,6 +166,90 @@ func (p *VrfProof) Decode(in [64]byte) error {
 	return nil
 }
 
+// NewVrfSignature creates a VrfSignature from a VRF output and proof
+func NewVrfSignature(out *VrfOutput, proof *VrfProof) *VrfSignature {
+	return &VrfSignature{
+		output: out,
+		proof:  proof,
+	}
+}
+
+// Output returns the VRF output of the signature
+func (sig *VrfSignature) Output() *VrfOutput {
+	return sig.output
+}
+
+// Proof returns the VRF proof of the signature
+func (sig *VrfSignature) Proof() *VrfProof {
+	return sig.proof
+}
+
+// Encode returns the 96-byte encoding of the signature: the 32-byte output followed by the 64-byte proof
+func (sig *VrfSignature) Encode() [VrfSignatureSize]byte {
+	enc := [VrfSignatureSize]byte{}
+	outenc := sig.output.Encode()
+	proofenc := sig.proof.Encode()
+	copy(enc[:32], outenc[:])
+	copy(enc[32:], proofenc[:])
+	return enc
+}
+
+// Decode sets the VrfSignature to the decoded input
+func (sig *VrfSignature) Decode(in [VrfSignatureSize]byte) error {
+	outenc := [32]byte{}
+	copy(outenc[:], in[:32])
+	out := new(VrfOutput)
+	err := out.Decode(outenc)
+	if err != nil {
+		return err
+	}
+
+	proofenc := [64]byte{}
+	copy(proofenc[:], in[32:])
+	proof := new(VrfProof)
+	err = proof.Decode(proofenc)
+	if err != nil {
+		return err
+	}
+
+	sig.output = out
+	sig.proof = proof
+	return nil
+}
+
+// VrfSignature returns the vrf signature given a secret key and transcript.
+func (kp *Keypair) VrfSignature(t *merlin.Transcript) (*VrfSignature, error) {
+	if kp.secretKey == nil {
+		return nil, errors.New("secretKey is nil")
+	}
+	return kp.secretKey.VrfSignature(t)
+}
+
+// VerifyVrfSignature verifies that the vrf signature is valid given the public key and transcript.
+func (kp *Keypair) VerifyVrfSignature(t *merlin.Transcript, sig *VrfSignature) (bool, error) {
+	if kp.publicKey == nil {
+		return false, errors.New("publicKey is nil")
+	}
+	return kp.publicKey.VerifyVrfSignature(t, sig)
+}
+
+// VrfSignature returns the vrf signature given a secret key and transcript.
+func (secretKey *SecretKey) VrfSignature(t *merlin.Transcript) (*VrfSignature, error) {
+	inout, proof, err := secretKey.VrfSign(t)
+	if err != nil {
+		return nil, err
+	}
+	return NewVrfSignature(inout.Output(), proof), nil
+}
+
+// VerifyVrfSignature verifies that the vrf signature is valid given the public key and transcript.
+func (publicKey *PublicKey) VerifyVrfSignature(t *merlin.Transcript, sig *VrfSignature) (bool, error) {
+	if sig == nil {
+		return false, errors.New("signature provided is nil")
+	}
+	return publicKey.VrfVerify(t, sig.output, sig.proof)
+}
+
 // VrfSign returns a vrf output and proof given a secret key and transcript.
 func (kp *Keypair) VrfSign(t *merlin.Transcript) (*VrfInOut, *VrfProof, error) {
 	if kp.secretKey == nil {
@@ -164,18 +266,72 @@ func (kp *Keypair) VrfVerify(t *merlin.Transcript, out *VrfOutput, proof *VrfPro
 	return kp.publicKey.VrfVerify(t, out, proof)
 }
 
+// VrfSignExtra returns a vrf output and proof given a secret key and transcript.
+// The proof is created on the extra transcript, authenticating any data committed to it.
+func (kp *Keypair) VrfSignExtra(t *merlin.Transcript, extra *merlin.Transcript) (*VrfInOut, *VrfProof, error) {
+	if kp.secretKey == nil {
+		return nil, nil, errors.New("secretKey is nil")
+	}
+	return kp.secretKey.VrfSignExtra(t, extra)
+}
+
+// VrfVerifyExtra verifies that the proof and output created are valid given the public key,
+// transcript and the extra transcript the proof was created on.
+func (kp *Keypair) VrfVerifyExtra(t *merlin.Transcript, out *VrfOutput, proof *VrfProof, extra *merlin.Transcript) (bool, error) {
+	if kp.publicKey == nil {
+		return false, errors.New("publicKey is nil")
+	}
+	return kp.publicKey.VrfVerifyExtra(t, out, proof, extra)
+}
+
+// VrfSignAfterCheck computes the vrf output for the transcript, and creates a proof only if
+// check returns true for it. If check returns false the returned proof is nil.
+func (kp *Keypair) VrfSignAfterCheck(t *merlin.Transcript, check func(*VrfInOut) bool) (*VrfInOut, *VrfProof, error) {
+	if kp.secretKey == nil {
+		return nil, nil, errors.New("secretKey is nil")
+	}
+	return kp.secretKey.VrfSignAfterCheck(t, check)
+}
+
+// VrfSignExtraAfterCheck computes the vrf output for the transcript, and creates a proof on
+// the extra transcript returned by check. If check returns nil the returned proof is nil.
+func (kp *Keypair) VrfSignExtraAfterCheck(t *merlin.Transcript, check func(*VrfInOut) *merlin.Transcript) (*VrfInOut, *VrfProof, error) {
+	if kp.secretKey == nil {
+		return nil, nil, errors.New("secretKey is nil")
+	}
+	return kp.secretKey.VrfSignExtraAfterCheck(t, check)
+}
+
+// VrfCreateHash creates a VRF input/output pair on the given transcript, without a proof.
+func (kp *Keypair) VrfCreateHash(t *merlin.Transcript) (*VrfInOut, error) {
+	if kp.secretKey == nil {
+		return nil, errors.New("secretKey is nil")
+	}
+	return kp.secretKey.VrfCreateHash(t)
+}
+
 // VrfSign returns a vrf output and proof given a secret key and transcript.
 func (secretKey *SecretKey) VrfSign(t *merlin.Transcript) (*VrfInOut, *VrfProof, error) {
+	return secretKey.VrfSignExtra(t, merlin.NewTranscript(VRFLabel))
+}
+
+// VrfSignExtra returns a vrf output and proof given a secret key and transcript.
+// The proof is created on the extra transcript, authenticating any data committed to it.
+// see: https://github.com/w3f/schnorrkel/blob/798ab3e0813aa478b520c5cf6dc6e02fd4e07f0a/src/vrf.rs
+func (secretKey *SecretKey) VrfSignExtra(t *merlin.Transcript, extra *merlin.Transcript) (*VrfInOut, *VrfProof, error) {
 	if t == nil {
 		return nil, nil, errors.New("transcript provided is nil")
 	}
 
-	p, err := secretKey.vrfCreateHash(t)
+	if extra == nil {
+		return nil, nil, errors.New("extra transcript provided is nil")
+	}
+
+	p, err := secretKey.VrfCreateHash(t)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	extra := merlin.NewTranscript(VRFLabel)
 	proof, err := secretKey.dleqProve(extra, p)
 	if err != nil {
 		return nil, nil, err
@@ -192,12 +348,6 @@ func (secretKey *SecretKey) dleqProve(t *merlin.Transcript, p *VrfInOut) (*VrfPr
 	}
 	pubenc := pub.Encode()
 
-	t.AppendMessage([]byte("proto-name"), []byte("DLEQProof"))
-	t.AppendMessage([]byte("vrf:h"), p.input.Encode([]byte{}))
-	if !kusamaVRF {
-		t.AppendMessage([]byte("vrf:pk"), pubenc[:])
-	}
-
 	// create random element R = g^r
 	// TODO: update toe use witness scalar
 	// https://github.com/w3f/schnorrkel/blob/master/src/vrf.rs#L620
@@ -207,18 +357,11 @@ func (secretKey *SecretKey) dleqProve(t *merlin.Transcript, p *VrfInOut) (*VrfPr
 	}
 	R := r255.NewElement()
 	R.ScalarBaseMult(r)
-	t.AppendMessage([]byte("vrf:R=g^r"), R.Encode([]byte{}))
 
 	// create hr := HashToElement(input)
-	hr := r255.NewElement().ScalarMult(r, p.input).Encode([]byte{})
-	t.AppendMessage([]byte("vrf:h^r"), hr)
-
-	if kusamaVRF {
-		t.AppendMessage([]byte("vrf:pk"), pubenc[:])
-	}
-	t.AppendMessage([]byte("vrf:h^sk"), p.output.Encode([]byte{}))
+	hr := r255.NewElement().ScalarMult(r, p.input)
 
-	c := challengeScalar(t, []byte("prove"))
+	c := dleqChallenge(t, pubenc, p, R, hr)
 	s := r255.NewScalar()
 	sc, err := ScalarFromBytes(secretKey.key)
 	if err != nil {
@@ -232,17 +375,69 @@ func (secretKey *SecretKey) dleqProve(t *merlin.Transcript, p *VrfInOut) (*VrfPr
 	}, nil
 }
 
-// vrfCreateHash creates a VRF input/output pair on the given transcript.
-func (secretKey *SecretKey) vrfCreateHash(t *merlin.Transcript) (*VrfInOut, error) {
+// VrfSignAfterCheck computes the vrf output for the transcript, and creates a proof only if
+// check returns true for it. If check returns false the returned proof is nil.
+// see: https://github.com/w3f/schnorrkel/blob/798ab3e0813aa478b520c5cf6dc6e02fd4e07f0a/src/vrf.rs
+func (secretKey *SecretKey) VrfSignAfterCheck(t *merlin.Transcript, check func(*VrfInOut) bool) (*VrfInOut, *VrfProof, error) {
+	if check == nil {
+		return nil, nil, errors.New("check provided is nil")
+	}
+
+	return secretKey.VrfSignExtraAfterCheck(t, func(p *VrfInOut) *merlin.Transcript {
+		if !check(p) {
+			return nil
+		}
+		return merlin.NewTranscript(VRFLabel)
+	})
+}
+
+// VrfSignExtraAfterCheck computes the vrf output for the transcript, and creates a proof on
+// the extra transcript returned by check. If check returns nil the returned proof is nil.
+func (secretKey *SecretKey) VrfSignExtraAfterCheck(t *merlin.Transcript, check func(*VrfInOut) *merlin.Transcript) (*VrfInOut, *VrfProof, error) {
+	if t == nil {
+		return nil, nil, errors.New("transcript provided is nil")
+	}
+
+	if check == nil {
+		return nil, nil, errors.New("check provided is nil")
+	}
+
+	p, err := secretKey.VrfCreateHash(t)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	extra := check(p)
+	if extra == nil {
+		return p, nil, nil
+	}
+
+	proof, err := secretKey.dleqProve(extra, p)
+	if err != nil {
+		return nil, nil, err
+	}
+	return p, proof, nil
+}
+
+// VrfCreateHash creates a VRF input/output pair on the given transcript, without a proof.
+func (secretKey *SecretKey) VrfCreateHash(t *merlin.Transcript) (*VrfInOut, error) {
+	if t == nil {
+		return nil, errors.New("transcript provided is nil")
+	}
+
 	pub, err := secretKey.Public()
 	if err != nil {
 		return nil, err
 	}
-	input := pub.vrfHash(t)
 
+	return secretKey.vrfCreateInOut(pub.vrfHash(t))
+}
+
+// vrfCreateInOut creates the VRF input/output pair for the given input point.
+func (secretKey *SecretKey) vrfCreateInOut(input *r255.Element) (*VrfInOut, error) {
 	output := r255.NewElement()
 	sc := r255.NewScalar()
-	err = sc.Decode(secretKey.key[:])
+	err := sc.Decode(secretKey.key[:])
 	if err != nil {
 		return nil, err
 	}
@@ -256,53 +451,69 @@ func (secretKey *SecretKey) vrfCreateHash(t *merlin.Transcript) (*VrfInOut, erro
 
 // VrfVerify verifies that the proof and output created are valid given the public key and transcript.
 func (publicKey *PublicKey) VrfVerify(t *merlin.Transcript, out *VrfOutput, proof *VrfProof) (bool, error) {
+	return publicKey.VrfVerifyExtra(t, out, proof, merlin.NewTranscript(VRFLabel))
+}
+
+// VrfVerifyExtra verifies that the proof and output created are valid given the public key,
+// transcript and the extra transcript the proof was created on.
+func (publicKey *PublicKey) VrfVerifyExtra(t *merlin.Transcript, out *VrfOutput, proof *VrfProof, extra *merlin.Transcript) (bool, error) {
+	_, ok, err := publicKey.VrfVerifyExtraInOut(t, out, proof, extra)
+	return ok, err
+}
+
+// VrfVerifyInOut verifies the output and proof like VrfVerify, and also returns the input and
+// output pair for the transcript, as rust-schnorrkel's vrf_verify does, so that callers can make
+// bytes from the verified output without hashing the transcript again. The pair is nil if the
+// proof is invalid.
+func (publicKey *PublicKey) VrfVerifyInOut(t *merlin.Transcript, out *VrfOutput, proof *VrfProof) (*VrfInOut, bool, error) {
+	return publicKey.VrfVerifyExtraInOut(t, out, proof, merlin.NewTranscript(VRFLabel))
+}
+
+// VrfVerifyExtraInOut is VrfVerifyInOut for a proof created on an extra transcript.
+func (publicKey *PublicKey) VrfVerifyExtraInOut(t *merlin.Transcript, out *VrfOutput, proof *VrfProof, extra *merlin.Transcript) (*VrfInOut, bool, error) {
 	if t == nil {
-		return false, errors.New("transcript provided is nil")
+		return nil, false, errors.New("transcript provided is nil")
+	}
+
+	if extra == nil {
+		return nil, false, errors.New("extra transcript provided is nil")
 	}
 
 	if out == nil {
-		return false, errors.New("output provided is nil")
+		return nil, false, errors.New("output provided is nil")
 	}
 
 	if proof == nil {
-		return false, errors.New("proof provided is nil")
+		return nil, false, errors.New("proof provided is nil")
 	}
 
 	if publicKey.key.Equal(publicKeyAtInfinity) == 1 {
-		return false, ErrPublicKeyAtInfinity
+		return nil, false, ErrPublicKeyAtInfinity
 	}
 
 	inout, err := out.AttachInput(publicKey, t)
 	if err != nil {
-		return false, err
+		return nil, false, err
+	}
+
+	ok, err := publicKey.dleqVerify(extra, inout, proof)
+	if err != nil || !ok {
+		return nil, false, err
 	}
 
-	t0 := merlin.NewTranscript(VRFLabel)
-	return publicKey.dleqVerify(t0, inout, proof)
+	return inout, true, nil
 }
 
 // dleqVerify verifies the corresponding dleq proof.
 func (publicKey *PublicKey) dleqVerify(t *merlin.Transcript, p *VrfInOut, proof *VrfProof) (bool, error) {
-	t.AppendMessage([]byte("proto-name"), []byte("DLEQProof"))
-	t.AppendMessage([]byte("vrf:h"), p.input.Encode([]byte{}))
-	if !kusamaVRF {
-		t.AppendMessage([]byte("vrf:pk"), publicKey.key.Encode([]byte{}))
-	}
-
 	// R = proof.c*pk + proof.s*g
 	R := r255.NewElement()
 	R.VarTimeDoubleScalarBaseMult(proof.c, publicKey.key, proof.s)
-	t.AppendMessage([]byte("vrf:R=g^r"), R.Encode([]byte{}))
 
 	// hr = proof.c * p.output + proof.s * p.input
 	hr := r255.NewElement().VarTimeMultiScalarMult([]*r255.Scalar{proof.c, proof.s}, []*r255.Element{p.output, p.input})
-	t.AppendMessage([]byte("vrf:h^r"), hr.Encode([]byte{}))
-	if kusamaVRF {
-		t.AppendMessage([]byte("vrf:pk"), publicKey.key.Encode([]byte{}))
-	}
-	t.AppendMessage([]byte("vrf:h^sk"), p.output.Encode([]byte{}))
 
-	cexpected := challengeScalar(t, []byte("prove"))
+	cexpected := dleqChallenge(t, publicKey.Encode(), p, R, hr)
 	if cexpected.Equal(proof.c) == 1 {
 		return true, nil
 	}
@@ -310,10 +521,388 @@ func (publicKey *PublicKey) dleqVerify(t *merlin.Transcript, p *VrfInOut, proof
 	return false, nil
 }
 
+// Batchable returns the batchable form of the proof given the public key and the VRF
+// input and output it was created for. It does not verify the proof.
+func (p *VrfProof) Batchable(publicKey *PublicKey, inout *VrfInOut) (*VrfProofBatchable, error) {
+	if publicKey == nil {
+		return nil, errors.New("public key provided is nil")
+	}
+
+	if inout == nil {
+		return nil, errors.New("input and output provided is nil")
+	}
+
+	// R = proof.c*pk + proof.s*g
+	R := r255.NewElement().VarTimeDoubleScalarBaseMult(p.c, publicKey.key, p.s)
+
+	// hr = proof.c * p.output + proof.s * p.input
+	hr := r255.NewElement().VarTimeMultiScalarMult([]*r255.Scalar{p.c, p.s}, []*r255.Element{inout.output, inout.input})
+
+	return &VrfProofBatchable{
+		r:  R,
+		hr: hr,
+		s:  p.s,
+	}, nil
+}
+
+// Shorten returns the short (c, s) form of the proof given the public key and the VRF
+// input and output it was created for by VrfSign. It does not verify the proof.
+// Proofs created by VrfSignExtra must be shortened with ShortenExtra.
+// see: https://github.com/w3f/schnorrkel/blob/798ab3e0813aa478b520c5cf6dc6e02fd4e07f0a/src/vrf.rs
+func (p *VrfProofBatchable) Shorten(publicKey *PublicKey, inout *VrfInOut) (*VrfProof, error) {
+	return p.ShortenExtra(publicKey, inout, merlin.NewTranscript(VRFLabel))
+}
+
+// ShortenExtra returns the short (c, s) form of the proof given the public key, the VRF
+// input and output, and the extra transcript it was created on by VrfSignExtra.
+// It does not verify the proof.
+func (p *VrfProofBatchable) ShortenExtra(publicKey *PublicKey, inout *VrfInOut, extra *merlin.Transcript) (*VrfProof, error) {
+	if publicKey == nil {
+		return nil, errors.New("public key provided is nil")
+	}
+
+	if inout == nil {
+		return nil, errors.New("input and output provided is nil")
+	}
+
+	if extra == nil {
+		return nil, errors.New("extra transcript provided is nil")
+	}
+
+	return &VrfProof{
+		c: dleqChallenge(extra, publicKey.Encode(), inout, p.r, p.hr),
+		s: p.s,
+	}, nil
+}
+
+// Encode returns a 96-byte encoded VrfProofBatchable
+func (p *VrfProofBatchable) Encode() [96]byte {
+	enc := [96]byte{}
+	copy(enc[:32], p.r.Encode([]byte{}))
+	copy(enc[32:64], p.hr.Encode([]byte{}))
+	copy(enc[64:], p.s.Encode([]byte{}))
+	return enc
+}
+
+// Decode sets the VrfProofBatchable to the decoded input
+func (p *VrfProofBatchable) Decode(in [96]byte) error {
+	R := r255.NewElement()
+	err := R.Decode(in[:32])
+	if err != nil {
+		return err
+	}
+
+	hr := r255.NewElement()
+	err = hr.Decode(in[32:64])
+	if err != nil {
+		return err
+	}
+
+	s := r255.NewScalar()
+	err = s.Decode(in[64:])
+	if err != nil {
+		return err
+	}
+
+	p.r = R
+	p.hr = hr
+	p.s = s
+	return nil
+}
+
+// VrfVerifyBatch verifies that the outputs and batchable proofs are valid given the public
+// keys and transcripts, checking all of them with two multiscalar multiplications.
+// Proofs created by VrfSignExtra must be verified with VrfVerifyBatchExtra.
+// see: https://github.com/w3f/schnorrkel/blob/798ab3e0813aa478b520c5cf6dc6e02fd4e07f0a/src/vrf.rs
+func VrfVerifyBatch(transcripts []*merlin.Transcript, outs []*VrfOutput, proofs []*VrfProofBatchable, pubkeys []*PublicKey) (bool, error) {
+	extras := make([]*merlin.Transcript, len(proofs))
+	for i := range extras {
+		extras[i] = merlin.NewTranscript(VRFLabel)
+	}
+
+	return VrfVerifyBatchExtra(transcripts, outs, proofs, pubkeys, extras)
+}
+
+// VrfVerifyBatchExtra verifies that the outputs and batchable proofs are valid given the
+// public keys, transcripts and the extra transcripts the proofs were created on.
+func VrfVerifyBatchExtra(transcripts []*merlin.Transcript, outs []*VrfOutput, proofs []*VrfProofBatchable, pubkeys []*PublicKey, extras []*merlin.Transcript) (bool, error) {
+	if len(transcripts) != len(outs) || len(outs) != len(proofs) || len(proofs) != len(pubkeys) || len(pubkeys) != len(extras) {
+		return false, errors.New("the number of transcripts, outputs, proofs, public keys, and extra transcripts must be equal")
+	}
+
+	inouts := make([]*VrfInOut, len(transcripts))
+	for i, t := range transcripts {
+		if outs[i] == nil {
+			return false, errors.New("output provided is nil")
+		}
+
+		if pubkeys[i] == nil {
+			return false, errors.New("public key provided is nil")
+		}
+
+		if extras[i] == nil {
+			return false, errors.New("extra transcript provided is nil")
+		}
+
+		var err error
+		inouts[i], err = outs[i].AttachInput(pubkeys[i], t)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return dleqVerifyBatch(inouts, proofs, pubkeys, extras)
+}
+
+// VrfsSign returns the vrf outputs for each of the transcripts and a single proof for all of them.
+func (kp *Keypair) VrfsSign(ts []*merlin.Transcript) ([]*VrfInOut, *VrfProof, error) {
+	if kp.secretKey == nil {
+		return nil, nil, errors.New("secretKey is nil")
+	}
+	return kp.secretKey.VrfsSign(ts)
+}
+
+// VrfsVerify verifies that the outputs and the single proof created by VrfsSign are valid
+// given the public key and transcripts.
+func (kp *Keypair) VrfsVerify(ts []*merlin.Transcript, outs []*VrfOutput, proof *VrfProof) (bool, error) {
+	if kp.publicKey == nil {
+		return false, errors.New("publicKey is nil")
+	}
+	return kp.publicKey.VrfsVerify(ts, outs, proof)
+}
+
+// VrfsSign returns the vrf outputs for each of the transcripts and a single proof for all of them.
+func (secretKey *SecretKey) VrfsSign(ts []*merlin.Transcript) ([]*VrfInOut, *VrfProof, error) {
+	return secretKey.VrfsSignExtra(ts, merlin.NewTranscript(VRFLabel))
+}
+
+// VrfsSignExtra returns the vrf outputs for each of the transcripts and a single proof for
+// all of them, created on the extra transcript.
+// see: https://github.com/w3f/schnorrkel/blob/798ab3e0813aa478b520c5cf6dc6e02fd4e07f0a/src/vrf.rs
+func (secretKey *SecretKey) VrfsSignExtra(ts []*merlin.Transcript, extra *merlin.Transcript) ([]*VrfInOut, *VrfProof, error) {
+	if len(ts) == 0 {
+		return nil, nil, errors.New("no transcripts provided")
+	}
+
+	if extra == nil {
+		return nil, nil, errors.New("extra transcript provided is nil")
+	}
+
+	ps := make([]*VrfInOut, len(ts))
+	for i, t := range ts {
+		var err error
+		ps[i], err = secretKey.VrfCreateHash(t)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	pub, err := secretKey.Public()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p, err := pub.VrfsMerge(ps)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	proof, err := secretKey.dleqProve(extra, p)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ps, proof, nil
+}
+
+// VrfsVerify verifies that the outputs and the single proof created by VrfsSign are valid
+// given the public key and transcripts.
+func (publicKey *PublicKey) VrfsVerify(ts []*merlin.Transcript, outs []*VrfOutput, proof *VrfProof) (bool, error) {
+	return publicKey.VrfsVerifyExtra(ts, outs, proof, merlin.NewTranscript(VRFLabel))
+}
+
+// VrfsVerifyExtra verifies that the outputs and the single proof created by VrfsSignExtra
+// are valid given the public key, transcripts and extra transcript.
+func (publicKey *PublicKey) VrfsVerifyExtra(ts []*merlin.Transcript, outs []*VrfOutput, proof *VrfProof, extra *merlin.Transcript) (bool, error) {
+	if len(ts) != len(outs) {
+		return false, errors.New("the number of transcripts and outputs must be equal")
+	}
+
+	if proof == nil {
+		return false, errors.New("proof provided is nil")
+	}
+
+	if extra == nil {
+		return false, errors.New("extra transcript provided is nil")
+	}
+
+	if publicKey.key.Equal(publicKeyAtInfinity) == 1 {
+		return false, ErrPublicKeyAtInfinity
+	}
+
+	ps := make([]*VrfInOut, len(ts))
+	for i, t := range ts {
+		if outs[i] == nil {
+			return false, errors.New("output provided is nil")
+		}
+
+		var err error
+		ps[i], err = outs[i].AttachInput(publicKey, t)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	p, err := publicKey.VrfsMerge(ps)
+	if err != nil {
+		return false, err
+	}
+
+	return publicKey.dleqVerify(extra, p, proof)
+}
+
+// VrfsMerge merges VRF input and output pairs from the same signer into a single pair,
+// which a single dleq proof can be created for. The pairs are weighted by 128-bit scalars from a
+// "MergeVRFs" transcript modelled on rust-schnorrkel's vrfs_merge, but the merge is not tested
+// against rust-schnorrkel, so proofs are only known to verify with this package.
+func (publicKey *PublicKey) VrfsMerge(ps []*VrfInOut) (*VrfInOut, error) {
+	if len(ps) == 0 {
+		return nil, errors.New("no input and output pairs provided")
+	}
+
+	for _, p := range ps {
+		if p == nil {
+			return nil, errors.New("input and output provided is nil")
+		}
+	}
+
+	zs := make([]*r255.Scalar, len(ps))
+	inputs := make([]*r255.Element, len(ps))
+	outputs := make([]*r255.Element, len(ps))
+	for i, p := range ps {
+		// merlin transcripts cannot be cloned, so the shared prefix is recommitted for each pair
+		t := publicKey.vrfsMergeTranscript(ps)
+		p.commit(t)
+		zs[i] = challengeScalar128(t)
+		inputs[i] = p.input
+		outputs[i] = p.output
+	}
+
+	return &VrfInOut{
+		input:  r255.NewElement().VarTimeMultiScalarMult(zs, inputs),
+		output: r255.NewElement().VarTimeMultiScalarMult(zs, outputs),
+	}, nil
+}
+
+func (publicKey *PublicKey) vrfsMergeTranscript(ps []*VrfInOut) *merlin.Transcript {
+	t := merlin.NewTranscript("MergeVRFs")
+	pubenc := publicKey.Encode()
+	t.AppendMessage([]byte("vrf:pk"), pubenc[:])
+	for _, p := range ps {
+		p.commit(t)
+	}
+	return t
+}
+
+// challengeScalar128 returns a scalar from 128 bits extracted from the transcript
+func challengeScalar128(t *merlin.Transcript) *r255.Scalar {
+	b := [32]byte{}
+	copy(b[:16], t.ExtractBytes([]byte(""), 16))
+	sc := r255.NewScalar()
+	// any 128-bit integer is a canonical scalar encoding
+	_ = sc.Decode(b[:])
+	return sc
+}
+
+// dleqVerifyBatch verifies a batch of batchable dleq proofs by checking
+//
+//	∑ z_i s_i B + ∑ z_i c_i P_i - ∑ z_i R_i = 0
+//	∑ z_i s_i input_i + ∑ z_i c_i output_i - ∑ z_i Hr_i = 0
+//
+// for random weights z_i.
+func dleqVerifyBatch(inouts []*VrfInOut, proofs []*VrfProofBatchable, pubkeys []*PublicKey, extras []*merlin.Transcript) (bool, error) {
+	n := len(proofs)
+	bs := r255.NewScalar()
+	zs := make([]*r255.Scalar, n)  // -z_i
+	zcs := make([]*r255.Scalar, n) // z_i c_i
+	zss := make([]*r255.Scalar, n) // z_i s_i
+	rs := make([]*r255.Element, n)
+	hrs := make([]*r255.Element, n)
+	pks := make([]*r255.Element, n)
+	outputs := make([]*r255.Element, n)
+	inputs := make([]*r255.Element, n)
+
+	for i, proof := range proofs {
+		if proof == nil {
+			return false, errors.New("proof provided is nil")
+		}
+
+		if pubkeys[i].key.Equal(publicKeyAtInfinity) == 1 {
+			return false, ErrPublicKeyAtInfinity
+		}
+
+		z, err := NewRandomScalar()
+		if err != nil {
+			return false, err
+		}
+
+		short, err := proof.ShortenExtra(pubkeys[i], inouts[i], extras[i])
+		if err != nil {
+			return false, err
+		}
+
+		zss[i] = r255.NewScalar().Multiply(z, proof.s)
+		bs.Add(bs, zss[i])
+		zcs[i] = r255.NewScalar().Multiply(z, short.c)
+		zs[i] = r255.NewScalar().Negate(z)
+
+		rs[i] = proof.r
+		hrs[i] = proof.hr
+		pks[i] = pubkeys[i].key
+		outputs[i] = inouts[i].output
+		inputs[i] = inouts[i].input
+	}
+
+	zero := r255.NewElement().Zero()
+
+	scalars := append(append(append([]*r255.Scalar{}, zs...), zcs...), bs)
+	points := append(append(append([]*r255.Element{}, rs...), pks...), r255.NewElement().Base())
+	if r255.NewElement().VarTimeMultiScalarMult(scalars, points).Equal(zero) != 1 {
+		return false, nil
+	}
+
+	scalars = append(append(append([]*r255.Scalar{}, zs...), zcs...), zss...)
+	points = append(append(append([]*r255.Element{}, hrs...), outputs...), inputs...)
+	return r255.NewElement().VarTimeMultiScalarMult(scalars, points).Equal(zero) == 1, nil
+}
+
+// dleqChallenge commits the DLEQ proof statement and commitments to the transcript and
+// returns the challenge scalar c.
+// see: https://github.com/w3f/schnorrkel/blob/798ab3e0813aa478b520c5cf6dc6e02fd4e07f0a/src/vrf.rs#L604
+func dleqChallenge(t *merlin.Transcript, pubenc [PublicKeySize]byte, p *VrfInOut, R, hr *r255.Element) *r255.Scalar {
+	t.AppendMessage([]byte("proto-name"), []byte("DLEQProof"))
+	t.AppendMessage([]byte("vrf:h"), p.input.Encode([]byte{}))
+	if !kusamaVRF {
+		t.AppendMessage([]byte("vrf:pk"), pubenc[:])
+	}
+
+	t.AppendMessage([]byte("vrf:R=g^r"), R.Encode([]byte{}))
+	t.AppendMessage([]byte("vrf:h^r"), hr.Encode([]byte{}))
+	if kusamaVRF {
+		t.AppendMessage([]byte("vrf:pk"), pubenc[:])
+	}
+	t.AppendMessage([]byte("vrf:h^sk"), p.output.Encode([]byte{}))
+
+	return challengeScalar(t, []byte("prove"))
+}
+
 // vrfHash hashes the transcript to a point.
 func (publicKey *PublicKey) vrfHash(t *merlin.Transcript) *r255.Element {
-	mt := TranscriptWithMalleabilityAddressed(t, publicKey)
-	hash := mt.ExtractBytes([]byte("VRFHash"), 64)
+	return vrfMalleableHash(TranscriptWithMalleabilityAddressed(t, publicKey))
+}
+
+// vrfMalleableHash hashes the transcript to a point without committing to a public key.
+func vrfMalleableHash(t *merlin.Transcript) *r255.Element {
+	hash := t.ExtractBytes([]byte("VRFHash"), 64)
 	point := r255.NewElement()
 	point.FromUniformBytes(hash)
 	return point
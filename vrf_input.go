@@ -0,0 +1,166 @@
+package schnorrkel
+
+import (
+	"errors"
+
+	"github.com/gtank/merlin"
+	r255 "github.com/gtank/ristretto255"
+)
+
+// VrfInputMode selects how a transcript is hashed to a VRF input point
+type VrfInputMode int
+
+const (
+	// VrfNonMalleable commits the signer's public key to the transcript before hashing it to a
+	// point, so every signer has a different input. This is the mode used by VrfSign and VrfVerify.
+	VrfNonMalleable VrfInputMode = iota
+
+	// VrfMalleable hashes the transcript to a point without a public key, so that every signer has
+	// the same input and their outputs on it can be compared.
+	VrfMalleable
+)
+
+// ErrVrfInputKeyMismatch is returned when a non-malleable VrfInput is used with a public key
+// other than the one it was created for
+var ErrVrfInputKeyMismatch = errors.New("non-malleable VRF input was created for another public key")
+
+// VrfInput is a precomputed VRF input point
+type VrfInput struct {
+	input *r255.Element
+	mode  VrfInputMode
+	pub   [PublicKeySize]byte // the public key committed to in VrfNonMalleable mode
+}
+
+// NewVrfInput hashes the transcript to a VRF input point using the given mode. The public key
+// of the signer is required in VrfNonMalleable mode, and ignored in VrfMalleable mode.
+// see: https://github.com/w3f/schnorrkel/blob/798ab3e0813aa478b520c5cf6dc6e02fd4e07f0a/src/vrf.rs
+func NewVrfInput(t *merlin.Transcript, pub *PublicKey, mode VrfInputMode) (*VrfInput, error) {
+	if t == nil {
+		return nil, errors.New("transcript provided is nil")
+	}
+
+	switch mode {
+	case VrfNonMalleable:
+		if pub == nil {
+			return nil, errors.New("public key provided is nil")
+		}
+		return &VrfInput{input: pub.vrfHash(t), mode: mode, pub: pub.Encode()}, nil
+	case VrfMalleable:
+		return &VrfInput{input: vrfMalleableHash(t), mode: mode}, nil
+	default:
+		return nil, errors.New("invalid VRF input mode")
+	}
+}
+
+// Mode returns the mode the input was created with
+func (in *VrfInput) Mode() VrfInputMode {
+	return in.mode
+}
+
+// Encode returns the 32-byte encoding of the input point
+func (in *VrfInput) Encode() [32]byte {
+	enc := [32]byte{}
+	copy(enc[:], in.input.Encode([]byte{}))
+	return enc
+}
+
+// checkKey returns an error if the input is non-malleable and was created for another public key
+func (in *VrfInput) checkKey(pub *PublicKey) error {
+	if in.mode == VrfNonMalleable && in.pub != pub.Encode() {
+		return ErrVrfInputKeyMismatch
+	}
+	return nil
+}
+
+// AttachVrfInput returns a VrfInOut pair from an output and a precomputed input
+func (out *VrfOutput) AttachVrfInput(in *VrfInput) (*VrfInOut, error) {
+	if in == nil {
+		return nil, errors.New("input provided is nil")
+	}
+
+	return &VrfInOut{
+		input:  in.input,
+		output: out.output,
+	}, nil
+}
+
+// VrfSignWithMode returns a vrf output and proof given a secret key and transcript, hashing the
+// transcript to the input point using the given mode.
+func (secretKey *SecretKey) VrfSignWithMode(t *merlin.Transcript, mode VrfInputMode) (*VrfInOut, *VrfProof, error) {
+	pub, err := secretKey.Public()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	in, err := NewVrfInput(t, pub, mode)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return secretKey.VrfSignInput(in)
+}
+
+// VrfSignInput returns a vrf output and proof given a secret key and a precomputed input.
+func (secretKey *SecretKey) VrfSignInput(in *VrfInput) (*VrfInOut, *VrfProof, error) {
+	if in == nil {
+		return nil, nil, errors.New("input provided is nil")
+	}
+
+	pub, err := secretKey.Public()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err = in.checkKey(pub); err != nil {
+		return nil, nil, err
+	}
+
+	p, err := secretKey.vrfCreateInOut(in.input)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	proof, err := secretKey.dleqProve(merlin.NewTranscript(VRFLabel), p)
+	if err != nil {
+		return nil, nil, err
+	}
+	return p, proof, nil
+}
+
+// VrfVerifyWithMode verifies that the proof and output created are valid given the public key and
+// transcript, hashing the transcript to the input point using the given mode.
+func (publicKey *PublicKey) VrfVerifyWithMode(t *merlin.Transcript, out *VrfOutput, proof *VrfProof, mode VrfInputMode) (bool, error) {
+	in, err := NewVrfInput(t, publicKey, mode)
+	if err != nil {
+		return false, err
+	}
+
+	return publicKey.VrfVerifyInput(in, out, proof)
+}
+
+// VrfVerifyInput verifies that the proof and output created are valid given the public key and a
+// precomputed input.
+func (publicKey *PublicKey) VrfVerifyInput(in *VrfInput, out *VrfOutput, proof *VrfProof) (bool, error) {
+	if out == nil {
+		return false, errors.New("output provided is nil")
+	}
+
+	if proof == nil {
+		return false, errors.New("proof provided is nil")
+	}
+
+	if publicKey.key.Equal(publicKeyAtInfinity) == 1 {
+		return false, ErrPublicKeyAtInfinity
+	}
+
+	inout, err := out.AttachVrfInput(in)
+	if err != nil {
+		return false, err
+	}
+
+	if err = in.checkKey(publicKey); err != nil {
+		return false, err
+	}
+
+	return publicKey.dleqVerify(merlin.NewTranscript(VRFLabel), inout, proof)
+}
@@ -0,0 +1,97 @@
+package schnorrkel
+
+import (
+	"testing"
+
+	"github.com/gtank/merlin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVrfSignWithMode_NonMalleable(t *testing.T) {
+	priv, pub, err := GenerateKeypair()
+	require.NoError(t, err)
+
+	inout, proof, err := priv.VrfSignWithMode(merlin.NewTranscript("vrf-test"), VrfNonMalleable)
+	require.NoError(t, err)
+
+	// the non-malleable mode is the one used by VrfSign and VrfVerify
+	expected, err := priv.VrfCreateHash(merlin.NewTranscript("vrf-test"))
+	require.NoError(t, err)
+	require.Equal(t, expected.Encode(), inout.Encode())
+
+	ok, err := pub.VrfVerify(merlin.NewTranscript("vrf-test"), inout.Output(), proof)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = pub.VrfVerifyWithMode(merlin.NewTranscript("vrf-test"), inout.Output(), proof, VrfNonMalleable)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = pub.VrfVerifyWithMode(merlin.NewTranscript("vrf-test"), inout.Output(), proof, VrfMalleable)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestVrfSignWithMode_Malleable(t *testing.T) {
+	priv1, pub1, err := GenerateKeypair()
+	require.NoError(t, err)
+	priv2, pub2, err := GenerateKeypair()
+	require.NoError(t, err)
+
+	// every signer has the same input point
+	in, err := NewVrfInput(merlin.NewTranscript("vrf-test"), nil, VrfMalleable)
+	require.NoError(t, err)
+	require.Equal(t, VrfMalleable, in.Mode())
+
+	inout1, proof1, err := priv1.VrfSignInput(in)
+	require.NoError(t, err)
+	inout2, proof2, err := priv2.VrfSignWithMode(merlin.NewTranscript("vrf-test"), VrfMalleable)
+	require.NoError(t, err)
+	require.Equal(t, inout1.Encode()[:32], inout2.Encode()[:32])
+	require.Equal(t, in.Encode(), [32]byte(inout1.Encode()[:32]))
+
+	ok, err := pub1.VrfVerifyInput(in, inout1.Output(), proof1)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = pub2.VrfVerifyWithMode(merlin.NewTranscript("vrf-test"), inout2.Output(), proof2, VrfMalleable)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = pub2.VrfVerifyInput(in, inout1.Output(), proof1)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	ok, err = pub1.VrfVerify(merlin.NewTranscript("vrf-test"), inout1.Output(), proof1)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestVrfInput_KeyMismatch(t *testing.T) {
+	priv1, pub1, err := GenerateKeypair()
+	require.NoError(t, err)
+	priv2, pub2, err := GenerateKeypair()
+	require.NoError(t, err)
+
+	in, err := NewVrfInput(merlin.NewTranscript("vrf-test"), pub1, VrfNonMalleable)
+	require.NoError(t, err)
+
+	_, _, err = priv2.VrfSignInput(in)
+	require.ErrorIs(t, err, ErrVrfInputKeyMismatch)
+
+	inout, proof, err := priv1.VrfSignInput(in)
+	require.NoError(t, err)
+
+	ok, err := pub1.VrfVerifyInput(in, inout.Output(), proof)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	_, err = pub2.VrfVerifyInput(in, inout.Output(), proof)
+	require.ErrorIs(t, err, ErrVrfInputKeyMismatch)
+
+	_, err = NewVrfInput(merlin.NewTranscript("vrf-test"), nil, VrfNonMalleable)
+	require.Error(t, err)
+
+	_, err = NewVrfInput(merlin.NewTranscript("vrf-test"), pub1, VrfInputMode(7))
+	require.Error(t, err)
+}
@@ -0,0 +1,50 @@
+package schnorrkel
+
+import (
+	"golang.org/x/crypto/chacha20"
+)
+
+// VrfRng is a deterministic stream of randomness derived from a VRF output, so anyone holding
+// the VRF output can reproduce it. It is seeded the way rust-schnorrkel's make_chacha_rng seeds
+// its ChaCha20Rng, and its keystream is tested against rand_chacha's vectors, but the two are not
+// tested together against rust-schnorrkel.
+type VrfRng struct {
+	cipher *chacha20.Cipher
+}
+
+// MakeRng returns a VrfRng seeded with 32 bytes from MakeBytes using the given context
+// https://github.com/w3f/schnorrkel/blob/798ab3e0813aa478b520c5cf6dc6e02fd4e07f0a/src/vrf.rs
+func (io *VrfInOut) MakeRng(context []byte) (*VrfRng, error) {
+	b, err := io.MakeBytes(32, context)
+	if err != nil {
+		return nil, err
+	}
+
+	seed := [32]byte{}
+	copy(seed[:], b)
+	return newVrfRng(seed), nil
+}
+
+func newVrfRng(seed [32]byte) *VrfRng {
+	// rand_chacha's ChaCha20Rng uses a 64-bit block counter and a zero stream id, which matches
+	// the IETF ChaCha20 keystream with a zero nonce for the first 2^32 blocks
+	cipher, err := chacha20.NewUnauthenticatedCipher(seed[:], make([]byte, chacha20.NonceSize))
+	if err != nil {
+		// only possible with invalid key or nonce sizes
+		panic(err)
+	}
+
+	return &VrfRng{
+		cipher: cipher,
+	}
+}
+
+// Read fills p with the next len(p) bytes of the stream. It never returns an error.
+// The stream is limited to 256 GiB, after which Read panics.
+func (r *VrfRng) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	r.cipher.XORKeyStream(p, p)
+	return len(p), nil
+}
@@ -0,0 +1,109 @@
+package schnorrkel
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"testing"
+
+	"github.com/gtank/merlin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVrfRng_ChaChaVector(t *testing.T) {
+	// ChaCha20 keystream for the all-zero key, as produced by rand_chacha's ChaCha20Rng::from_seed([0; 32])
+	expected, err := hex.DecodeString("76b8e0ada0f13d90405d6ae55386bd28bdd219b8a08ded1aa836efcc8b770dc7da41597c5157488d7724e03fb8d84a376a43b8f41518a11cc387b669b2ee6586")
+	require.NoError(t, err)
+
+	out := make([]byte, len(expected))
+	_, err = io.ReadFull(newVrfRng([32]byte{}), out)
+	require.NoError(t, err)
+	require.Equal(t, expected, out)
+}
+
+func TestVrfRng_RandChaChaVectors(t *testing.T) {
+	// test_chacha_true_values_b and test_chacha_true_values_c of rand_chacha 0.9, which skip
+	// to blocks 1 and 2 of the stream
+	for _, tc := range []struct {
+		seed     [32]byte
+		block    int
+		expected []uint32
+	}{
+		{
+			seed:  [32]byte{31: 1},
+			block: 1,
+			expected: []uint32{
+				0x2452eb3a, 0x9249f8ec, 0x8d829d9b, 0xddd4ceb1, 0xe8252083, 0x60818b01, 0xf38422b8, 0x5aaa49c9,
+				0xbb00ca8e, 0xda3ba7b4, 0xc4b592d1, 0xfdf2732f, 0x4436274e, 0x2561b3c8, 0xebdd4aa6, 0xa0136c00,
+			},
+		},
+		{
+			seed:  [32]byte{1: 0xff},
+			block: 2,
+			expected: []uint32{
+				0xfb4dd572, 0x4bc42ef1, 0xdf922636, 0x327f1394, 0xa78dea8f, 0x5e269039, 0xa1bebbc1, 0xcaf09aae,
+				0xa25ab213, 0x48a6b46c, 0x1b9d9bcb, 0x092c5be6, 0x546ca624, 0x1bec45d5, 0x87f47473, 0x96f0992e,
+			},
+		},
+	} {
+		r := newVrfRng(tc.seed)
+		_, err := io.ReadFull(r, make([]byte, 64*tc.block))
+		require.NoError(t, err)
+
+		block := make([]byte, 64)
+		_, err = io.ReadFull(r, block)
+		require.NoError(t, err)
+		for i, w := range tc.expected {
+			require.Equal(t, w, binary.LittleEndian.Uint32(block[4*i:]))
+		}
+	}
+}
+
+func TestVrfInOut_MakeRng(t *testing.T) {
+	priv, pub, err := GenerateKeypair()
+	require.NoError(t, err)
+
+	inout, proof, err := priv.VrfSign(merlin.NewTranscript("vrf-test"))
+	require.NoError(t, err)
+
+	// a verifier reconstructs the same stream from the output
+	verified, err := inout.Output().AttachInput(pub, merlin.NewTranscript("vrf-test"))
+	require.NoError(t, err)
+	ok, err := pub.VrfVerify(merlin.NewTranscript("vrf-test"), inout.Output(), proof)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	rng, err := inout.MakeRng([]byte("context"))
+	require.NoError(t, err)
+	out := make([]byte, 1000)
+	_, err = io.ReadFull(rng, out)
+	require.NoError(t, err)
+
+	// the seed is the 32 bytes of MakeBytes, as in make_chacha_rng
+	seed, err := inout.MakeBytes(32, []byte("context"))
+	require.NoError(t, err)
+	expected := make([]byte, len(out))
+	_, err = io.ReadFull(newVrfRng([32]byte(seed)), expected)
+	require.NoError(t, err)
+	require.Equal(t, expected, out)
+
+	// reading in chunks gives the same stream
+	rng, err = verified.MakeRng([]byte("context"))
+	require.NoError(t, err)
+	out2 := make([]byte, 0, len(out))
+	for _, n := range []int{1, 63, 64, 100, 772} {
+		buf := make([]byte, n)
+		_, err = io.ReadFull(rng, buf)
+		require.NoError(t, err)
+		out2 = append(out2, buf...)
+	}
+	require.Equal(t, out, out2)
+
+	// a different context gives a different stream
+	rng, err = inout.MakeRng([]byte("other"))
+	require.NoError(t, err)
+	out3 := make([]byte, len(out))
+	_, err = io.ReadFull(rng, out3)
+	require.NoError(t, err)
+	require.NotEqual(t, out, out3)
+}
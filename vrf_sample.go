@@ -0,0 +1,171 @@
+package schnorrkel
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/bits"
+
+	"github.com/gtank/merlin"
+)
+
+var (
+	// ErrInvalidSampleRange is returned when sampling from an empty range, or more items than the range holds
+	ErrInvalidSampleRange = errors.New("invalid sample range")
+	// ErrInvalidVrfProof is returned by the sampling verification helpers when the VRF proof is invalid
+	ErrInvalidVrfProof = errors.New("invalid VRF proof")
+)
+
+// Uint64 returns the next 8 bytes of the stream as a little-endian integer
+func (r *VrfRng) Uint64() uint64 {
+	b := [8]byte{}
+	_, _ = r.Read(b[:])
+	return binary.LittleEndian.Uint64(b[:])
+}
+
+// Uint64n returns a uniform integer in [0, n) without modulo bias, using Lemire's
+// multiply-and-reject method
+// see: https://arxiv.org/abs/1805.10941
+func (r *VrfRng) Uint64n(n uint64) (uint64, error) {
+	if n == 0 {
+		return 0, ErrInvalidSampleRange
+	}
+
+	hi, lo := bits.Mul64(r.Uint64(), n)
+	if lo < n {
+		// -n % n is 2^64 mod n, the number of low values that would bias the result
+		threshold := -n % n
+		for lo < threshold {
+			hi, lo = bits.Mul64(r.Uint64(), n)
+		}
+	}
+
+	return hi, nil
+}
+
+// Shuffle pseudo-randomizes the order of n elements with a Fisher-Yates shuffle, calling swap
+// to swap the elements with indexes i and j
+func (r *VrfRng) Shuffle(n int, swap func(i, j int)) {
+	for i := n - 1; i > 0; i-- {
+		// i+1 is never zero
+		j, _ := r.Uint64n(uint64(i + 1))
+		swap(i, int(j))
+	}
+}
+
+// Sample returns k distinct integers from [0, n) in the order they were drawn. It runs a
+// partial Fisher-Yates shuffle over a sparse permutation, so it uses O(k) memory for any n.
+func (r *VrfRng) Sample(k, n int) ([]int, error) {
+	if n <= 0 || k < 0 || k > n {
+		return nil, ErrInvalidSampleRange
+	}
+
+	// swapped holds the entries of the permutation of [0, n) that differ from the identity
+	swapped := make(map[int]int, k)
+	at := func(i int) int {
+		if v, has := swapped[i]; has {
+			return v
+		}
+		return i
+	}
+
+	sample := make([]int, k)
+	for i := range sample {
+		// n-i is never zero since k <= n
+		j64, _ := r.Uint64n(uint64(n - i))
+		j := i + int(j64)
+		sample[i] = at(j)
+		swapped[j] = at(i)
+	}
+
+	return sample, nil
+}
+
+// SampleUniform returns a uniform integer in [0, n) from the VRF output and context
+func (io *VrfInOut) SampleUniform(context []byte, n uint64) (uint64, error) {
+	r, err := io.MakeRng(context)
+	if err != nil {
+		return 0, err
+	}
+
+	return r.Uint64n(n)
+}
+
+// SampleK returns k distinct integers from [0, n) from the VRF output and context, such as a
+// committee of k out of n members
+func (io *VrfInOut) SampleK(context []byte, k, n int) ([]int, error) {
+	r, err := io.MakeRng(context)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Sample(k, n)
+}
+
+// Shuffle returns a permutation of [0, n) from the VRF output and context
+func (io *VrfInOut) Shuffle(context []byte, n int) ([]int, error) {
+	if n < 0 {
+		return nil, ErrInvalidSampleRange
+	}
+
+	r, err := io.MakeRng(context)
+	if err != nil {
+		return nil, err
+	}
+
+	perm := make([]int, n)
+	for i := range perm {
+		perm[i] = i
+	}
+
+	r.Shuffle(n, func(i, j int) {
+		perm[i], perm[j] = perm[j], perm[i]
+	})
+	return perm, nil
+}
+
+// VrfVerifySampleUniform verifies the VRF output and proof for the transcript, and returns the
+// uniform integer in [0, n) sampled from it with the context
+func (publicKey *PublicKey) VrfVerifySampleUniform(t *merlin.Transcript, out *VrfOutput, proof *VrfProof, context []byte, n uint64) (uint64, error) {
+	inout, err := publicKey.verifyInOut(t, out, proof)
+	if err != nil {
+		return 0, err
+	}
+
+	return inout.SampleUniform(context, n)
+}
+
+// VrfVerifySampleK verifies the VRF output and proof for the transcript, and returns the k
+// distinct integers from [0, n) sampled from it with the context
+func (publicKey *PublicKey) VrfVerifySampleK(t *merlin.Transcript, out *VrfOutput, proof *VrfProof, context []byte, k, n int) ([]int, error) {
+	inout, err := publicKey.verifyInOut(t, out, proof)
+	if err != nil {
+		return nil, err
+	}
+
+	return inout.SampleK(context, k, n)
+}
+
+// VrfVerifyShuffle verifies the VRF output and proof for the transcript, and returns the
+// permutation of [0, n) made from it with the context
+func (publicKey *PublicKey) VrfVerifyShuffle(t *merlin.Transcript, out *VrfOutput, proof *VrfProof, context []byte, n int) ([]int, error) {
+	inout, err := publicKey.verifyInOut(t, out, proof)
+	if err != nil {
+		return nil, err
+	}
+
+	return inout.Shuffle(context, n)
+}
+
+// verifyInOut is VrfVerifyInOut, which returns ErrInvalidVrfProof if the proof is invalid
+func (publicKey *PublicKey) verifyInOut(t *merlin.Transcript, out *VrfOutput, proof *VrfProof) (*VrfInOut, error) {
+	inout, ok, err := publicKey.VrfVerifyInOut(t, out, proof)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, ErrInvalidVrfProof
+	}
+
+	return inout, nil
+}
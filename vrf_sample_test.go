@@ -0,0 +1,106 @@
+package schnorrkel
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/gtank/merlin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVrfRng_Uint64(t *testing.T) {
+	// first 8 bytes of the zero-key ChaCha20 keystream, 76b8e0ada0f13d90
+	r := newVrfRng([32]byte{})
+	require.Equal(t, uint64(0x903df1a0ade0b876), r.Uint64())
+}
+
+func TestVrfRng_Uint64n(t *testing.T) {
+	r := newVrfRng([32]byte{1})
+
+	_, err := r.Uint64n(0)
+	require.ErrorIs(t, err, ErrInvalidSampleRange)
+
+	counts := make([]int, 6)
+	for i := 0; i < 6000; i++ {
+		v, err := r.Uint64n(6)
+		require.NoError(t, err)
+		require.Less(t, v, uint64(6))
+		counts[v]++
+	}
+
+	for _, c := range counts {
+		require.InDelta(t, 1000, c, 150)
+	}
+
+	// a range just above 2^63 rejects almost half of all values
+	n := uint64(1)<<63 + 1
+	for i := 0; i < 100; i++ {
+		v, err := r.Uint64n(n)
+		require.NoError(t, err)
+		require.Less(t, v, n)
+	}
+}
+
+func TestVrfRng_Sample(t *testing.T) {
+	r := newVrfRng([32]byte{2})
+
+	sample, err := r.Sample(10, 1000000)
+	require.NoError(t, err)
+	require.Len(t, sample, 10)
+
+	seen := make(map[int]struct{})
+	for _, v := range sample {
+		require.Less(t, v, 1000000)
+		seen[v] = struct{}{}
+	}
+	require.Len(t, seen, 10)
+
+	// sampling every element gives a permutation
+	sample, err = r.Sample(50, 50)
+	require.NoError(t, err)
+	sort.Ints(sample)
+	for i, v := range sample {
+		require.Equal(t, i, v)
+	}
+
+	_, err = r.Sample(51, 50)
+	require.ErrorIs(t, err, ErrInvalidSampleRange)
+	_, err = r.Sample(0, 0)
+	require.ErrorIs(t, err, ErrInvalidSampleRange)
+}
+
+func TestVrfVerifySample(t *testing.T) {
+	priv, pub, err := GenerateKeypair()
+	require.NoError(t, err)
+
+	inout, proof, err := priv.VrfSign(merlin.NewTranscript("committee"))
+	require.NoError(t, err)
+
+	uniform, err := inout.SampleUniform([]byte("leader"), 100)
+	require.NoError(t, err)
+	committee, err := inout.SampleK([]byte("committee"), 5, 100)
+	require.NoError(t, err)
+	shuffled, err := inout.Shuffle([]byte("order"), 20)
+	require.NoError(t, err)
+
+	sorted := append([]int{}, shuffled...)
+	sort.Ints(sorted)
+	for i, v := range sorted {
+		require.Equal(t, i, v)
+	}
+
+	vuniform, err := pub.VrfVerifySampleUniform(merlin.NewTranscript("committee"), inout.Output(), proof, []byte("leader"), 100)
+	require.NoError(t, err)
+	require.Equal(t, uniform, vuniform)
+
+	vcommittee, err := pub.VrfVerifySampleK(merlin.NewTranscript("committee"), inout.Output(), proof, []byte("committee"), 5, 100)
+	require.NoError(t, err)
+	require.Equal(t, committee, vcommittee)
+
+	vshuffled, err := pub.VrfVerifyShuffle(merlin.NewTranscript("committee"), inout.Output(), proof, []byte("order"), 20)
+	require.NoError(t, err)
+	require.Equal(t, shuffled, vshuffled)
+
+	_, err = pub.VrfVerifySampleK(merlin.NewTranscript("other"), inout.Output(), proof, []byte("committee"), 5, 100)
+	require.ErrorIs(t, err, ErrInvalidVrfProof)
+}
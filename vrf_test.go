@@ -1,6 +1,7 @@
 package schnorrkel
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/gtank/merlin"
@@ -242,3 +243,348 @@ func TestVRFVerify_PublicKeyAtInfinity(t *testing.T) {
 	_, err = pub.VrfVerify(verifyTranscript, inout.Output(), proof)
 	require.ErrorIs(t, err, ErrPublicKeyAtInfinity)
 }
+
+func TestVrfProofBatchable(t *testing.T) {
+	priv, pub, err := GenerateKeypair()
+	require.NoError(t, err)
+
+	inout, proof, err := priv.VrfSign(merlin.NewTranscript("vrf-test"))
+	require.NoError(t, err)
+
+	batchable, err := proof.Batchable(pub, inout)
+	require.NoError(t, err)
+
+	enc := batchable.Encode()
+	batchable2 := new(VrfProofBatchable)
+	err = batchable2.Decode(enc)
+	require.NoError(t, err)
+	require.Equal(t, enc, batchable2.Encode())
+
+	short, err := batchable2.Shorten(pub, inout)
+	require.NoError(t, err)
+	require.Equal(t, proof.Encode(), short.Encode())
+}
+
+func newVrfBatchInputs(t *testing.T, num int) ([]*merlin.Transcript, []*VrfOutput, []*VrfProofBatchable, []*PublicKey) {
+	transcripts := make([]*merlin.Transcript, num)
+	outs := make([]*VrfOutput, num)
+	proofs := make([]*VrfProofBatchable, num)
+	pubkeys := make([]*PublicKey, num)
+
+	for i := 0; i < num; i++ {
+		priv, pub, err := GenerateKeypair()
+		require.NoError(t, err)
+
+		inout, proof, err := priv.VrfSign(merlin.NewTranscript(fmt.Sprintf("vrf-test_%d", i)))
+		require.NoError(t, err)
+
+		proofs[i], err = proof.Batchable(pub, inout)
+		require.NoError(t, err)
+
+		transcripts[i] = merlin.NewTranscript(fmt.Sprintf("vrf-test_%d", i))
+		outs[i] = inout.Output()
+		pubkeys[i] = pub
+	}
+
+	return transcripts, outs, proofs, pubkeys
+}
+
+func TestVrfVerifyBatch(t *testing.T) {
+	transcripts, outs, proofs, pubkeys := newVrfBatchInputs(t, 16)
+	ok, err := VrfVerifyBatch(transcripts, outs, proofs, pubkeys)
+	require.NoError(t, err)
+	require.True(t, ok)
+}
+
+func TestVrfVerifyBatch_Bad(t *testing.T) {
+	transcripts, outs, proofs, pubkeys := newVrfBatchInputs(t, 16)
+	transcripts[3] = merlin.NewTranscript("vrf-test_999")
+	ok, err := VrfVerifyBatch(transcripts, outs, proofs, pubkeys)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	transcripts, outs, proofs, pubkeys = newVrfBatchInputs(t, 16)
+	outs[5], outs[6] = outs[6], outs[5]
+	ok, err = VrfVerifyBatch(transcripts, outs, proofs, pubkeys)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	_, err = VrfVerifyBatch(transcripts[:3], outs, proofs, pubkeys)
+	require.Error(t, err)
+}
+
+func TestVrfVerifyBatchExtra(t *testing.T) {
+	num := 8
+	transcripts := make([]*merlin.Transcript, num)
+	outs := make([]*VrfOutput, num)
+	proofs := make([]*VrfProofBatchable, num)
+	pubkeys := make([]*PublicKey, num)
+
+	newExtra := func(i int) *merlin.Transcript {
+		extra := merlin.NewTranscript("vrf-extra")
+		extra.AppendMessage([]byte("data"), []byte(fmt.Sprintf("hello_%d", i)))
+		return extra
+	}
+
+	newExtras := func() []*merlin.Transcript {
+		extras := make([]*merlin.Transcript, num)
+		for i := range extras {
+			extras[i] = newExtra(i)
+		}
+		return extras
+	}
+
+	for i := 0; i < num; i++ {
+		priv, pub, err := GenerateKeypair()
+		require.NoError(t, err)
+
+		inout, proof, err := priv.VrfSignExtra(merlin.NewTranscript(fmt.Sprintf("vrf-test_%d", i)), newExtra(i))
+		require.NoError(t, err)
+
+		proofs[i], err = proof.Batchable(pub, inout)
+		require.NoError(t, err)
+
+		short, err := proofs[i].ShortenExtra(pub, inout, newExtra(i))
+		require.NoError(t, err)
+		require.Equal(t, proof.Encode(), short.Encode())
+
+		// without the extra transcript the proof cannot be shortened
+		short, err = proofs[i].Shorten(pub, inout)
+		require.NoError(t, err)
+		require.NotEqual(t, proof.Encode(), short.Encode())
+
+		outs[i] = inout.Output()
+		pubkeys[i] = pub
+	}
+
+	newTranscripts := func() []*merlin.Transcript {
+		for i := range transcripts {
+			transcripts[i] = merlin.NewTranscript(fmt.Sprintf("vrf-test_%d", i))
+		}
+		return transcripts
+	}
+
+	ok, err := VrfVerifyBatchExtra(newTranscripts(), outs, proofs, pubkeys, newExtras())
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// proofs created on an extra transcript are rejected by VrfVerifyBatch
+	ok, err = VrfVerifyBatch(newTranscripts(), outs, proofs, pubkeys)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	extras := newExtras()
+	extras[2] = newExtra(99)
+	ok, err = VrfVerifyBatchExtra(newTranscripts(), outs, proofs, pubkeys, extras)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	_, err = VrfVerifyBatchExtra(newTranscripts(), outs, proofs, pubkeys, extras[:3])
+	require.Error(t, err)
+}
+
+func TestVrfSignExtraAndVerifyExtra(t *testing.T) {
+	priv, pub, err := GenerateKeypair()
+	require.NoError(t, err)
+	kp := NewKeypair(pub, priv)
+
+	newExtra := func(data string) *merlin.Transcript {
+		extra := merlin.NewTranscript("vrf-extra")
+		extra.AppendMessage([]byte("data"), []byte(data))
+		return extra
+	}
+
+	inout, proof, err := kp.VrfSignExtra(merlin.NewTranscript("vrf-test"), newExtra("hello"))
+	require.NoError(t, err)
+
+	ok, err := kp.VrfVerifyExtra(merlin.NewTranscript("vrf-test"), inout.Output(), proof, newExtra("hello"))
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// the proof commits to the extra data
+	ok, err = pub.VrfVerifyExtra(merlin.NewTranscript("vrf-test"), inout.Output(), proof, newExtra("goodbye"))
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	ok, err = pub.VrfVerify(merlin.NewTranscript("vrf-test"), inout.Output(), proof)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// the output does not depend on the extra data
+	inout2, _, err := priv.VrfSign(merlin.NewTranscript("vrf-test"))
+	require.NoError(t, err)
+	require.Equal(t, inout.Encode(), inout2.Encode())
+
+	_, _, err = priv.VrfSignExtra(merlin.NewTranscript("vrf-test"), nil)
+	require.Error(t, err)
+}
+
+func TestVrfSignAfterCheck(t *testing.T) {
+	priv, pub, err := GenerateKeypair()
+	require.NoError(t, err)
+	kp := NewKeypair(pub, priv)
+
+	expected, err := kp.VrfCreateHash(merlin.NewTranscript("vrf-test"))
+	require.NoError(t, err)
+
+	inout, proof, err := kp.VrfSignAfterCheck(merlin.NewTranscript("vrf-test"), func(p *VrfInOut) bool {
+		require.Equal(t, expected.Encode(), p.Encode())
+		return false
+	})
+	require.NoError(t, err)
+	require.Nil(t, proof)
+	require.Equal(t, expected.Encode(), inout.Encode())
+
+	inout, proof, err = kp.VrfSignAfterCheck(merlin.NewTranscript("vrf-test"), func(p *VrfInOut) bool {
+		return true
+	})
+	require.NoError(t, err)
+	require.NotNil(t, proof)
+
+	ok, err := pub.VrfVerify(merlin.NewTranscript("vrf-test"), inout.Output(), proof)
+	require.NoError(t, err)
+	require.True(t, ok)
+}
+
+func TestVrfSignExtraAfterCheck(t *testing.T) {
+	priv, pub, err := GenerateKeypair()
+	require.NoError(t, err)
+
+	newExtra := func() *merlin.Transcript {
+		extra := merlin.NewTranscript("vrf-extra")
+		extra.AppendMessage([]byte("data"), []byte("hello"))
+		return extra
+	}
+
+	inout, proof, err := priv.VrfSignExtraAfterCheck(merlin.NewTranscript("vrf-test"), func(p *VrfInOut) *merlin.Transcript {
+		return newExtra()
+	})
+	require.NoError(t, err)
+
+	ok, err := pub.VrfVerifyExtra(merlin.NewTranscript("vrf-test"), inout.Output(), proof, newExtra())
+	require.NoError(t, err)
+	require.True(t, ok)
+}
+
+func newVrfsTranscripts(num int) []*merlin.Transcript {
+	ts := make([]*merlin.Transcript, num)
+	for i := range ts {
+		ts[i] = merlin.NewTranscript(fmt.Sprintf("vrf-test_%d", i))
+	}
+	return ts
+}
+
+func TestVrfsSignAndVerify(t *testing.T) {
+	priv, pub, err := GenerateKeypair()
+	require.NoError(t, err)
+	kp := NewKeypair(pub, priv)
+
+	num := 5
+	inouts, proof, err := kp.VrfsSign(newVrfsTranscripts(num))
+	require.NoError(t, err)
+	require.Len(t, inouts, num)
+
+	outs := make([]*VrfOutput, num)
+	for i, inout := range inouts {
+		// each output is the same as a single vrf output for the transcript
+		expected, err := priv.VrfCreateHash(merlin.NewTranscript(fmt.Sprintf("vrf-test_%d", i)))
+		require.NoError(t, err)
+		require.Equal(t, expected.Encode(), inout.Encode())
+		outs[i] = inout.Output()
+	}
+
+	ok, err := kp.VrfsVerify(newVrfsTranscripts(num), outs, proof)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	outs[1], outs[2] = outs[2], outs[1]
+	ok, err = pub.VrfsVerify(newVrfsTranscripts(num), outs, proof)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	_, err = pub.VrfsVerify(newVrfsTranscripts(num-1), outs, proof)
+	require.Error(t, err)
+
+	_, _, err = priv.VrfsSign(nil)
+	require.Error(t, err)
+}
+
+func TestVrfsSignExtraAndVerifyExtra(t *testing.T) {
+	priv, pub, err := GenerateKeypair()
+	require.NoError(t, err)
+
+	inouts, proof, err := priv.VrfsSignExtra(newVrfsTranscripts(3), merlin.NewTranscript("vrf-extra"))
+	require.NoError(t, err)
+
+	outs := []*VrfOutput{inouts[0].Output(), inouts[1].Output(), inouts[2].Output()}
+	ok, err := pub.VrfsVerifyExtra(newVrfsTranscripts(3), outs, proof, merlin.NewTranscript("vrf-extra"))
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = pub.VrfsVerify(newVrfsTranscripts(3), outs, proof)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestVrfSignature(t *testing.T) {
+	priv, pub, err := GenerateKeypair()
+	require.NoError(t, err)
+	kp := NewKeypair(pub, priv)
+
+	sig, err := kp.VrfSignature(merlin.NewTranscript("vrf-test"))
+	require.NoError(t, err)
+
+	enc := sig.Encode()
+	outenc := sig.Output().Encode()
+	proofenc := sig.Proof().Encode()
+	require.Equal(t, outenc[:], enc[:32])
+	require.Equal(t, proofenc[:], enc[32:])
+
+	sig2 := new(VrfSignature)
+	err = sig2.Decode(enc)
+	require.NoError(t, err)
+	require.Equal(t, enc, sig2.Encode())
+
+	ok, err := kp.VerifyVrfSignature(merlin.NewTranscript("vrf-test"), sig2)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = pub.VerifyVrfSignature(merlin.NewTranscript("vrf-other"), sig2)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// the output of the signature is the output of VrfSign
+	inout, err := priv.VrfCreateHash(merlin.NewTranscript("vrf-test"))
+	require.NoError(t, err)
+	require.Equal(t, inout.Output().Encode(), sig.Output().Encode())
+
+	enc[0] ^= 0xff
+	require.Error(t, sig2.Decode(enc))
+}
+
+func TestVrfVerifyInOut(t *testing.T) {
+	priv, pub, err := GenerateKeypair()
+	require.NoError(t, err)
+
+	inout, proof, err := priv.VrfSign(merlin.NewTranscript("vrf-test"))
+	require.NoError(t, err)
+
+	verified, ok, err := pub.VrfVerifyInOut(merlin.NewTranscript("vrf-test"), inout.Output(), proof)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, inout.input.Encode([]byte{}), verified.input.Encode([]byte{}))
+	require.Equal(t, inout.output.Encode([]byte{}), verified.output.Encode([]byte{}))
+
+	verified, ok, err = pub.VrfVerifyInOut(merlin.NewTranscript("other"), inout.Output(), proof)
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.Nil(t, verified)
+
+	extra := func() *merlin.Transcript { return merlin.NewTranscript("vrf-extra") }
+	inout, proof, err = priv.VrfSignExtra(merlin.NewTranscript("vrf-test"), extra())
+	require.NoError(t, err)
+	verified, ok, err = pub.VrfVerifyExtraInOut(merlin.NewTranscript("vrf-test"), inout.Output(), proof, extra())
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, inout.input.Encode([]byte{}), verified.input.Encode([]byte{}))
+}
@@ -0,0 +1,800 @@
+package schnorrkel
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/gtank/merlin"
+	r255 "github.com/gtank/ristretto255"
+)
+
+const (
+	// VrfPartialOutputSize is the length in bytes of an encoded VrfPartialOutput
+	VrfPartialOutputSize = 4 + 32 + 64
+	// VrfThresholdCommitmentSize is the length in bytes of an encoded VrfThresholdCommitment
+	VrfThresholdCommitmentSize = 4 + 4*32 + 64
+	// VrfThresholdResponseSize is the length in bytes of an encoded VrfThresholdResponse
+	VrfThresholdResponseSize = 4 + 32
+)
+
+var (
+	// ErrInvalidThreshold is returned when a threshold is zero or greater than the number of shares
+	ErrInvalidThreshold = errors.New("threshold must be between 1 and the number of shares")
+	// ErrInvalidShareIndex is returned for a share index that is zero or not part of the group
+	ErrInvalidShareIndex = errors.New("invalid share index")
+	// ErrDuplicateShareIndex is returned when the same share index is given more than once
+	ErrDuplicateShareIndex = errors.New("duplicate share index")
+	// ErrNotEnoughShares is returned when fewer than threshold shares take part in a combination
+	ErrNotEnoughShares = errors.New("not enough shares to reach the threshold")
+	// ErrThresholdNonceUsed is returned when a VrfThresholdNonce is used a second time
+	ErrThresholdNonceUsed = errors.New("threshold nonce has already been used")
+	// ErrThresholdCommitmentMissing is returned when a signer's own commitment is not part of the signing set
+	ErrThresholdCommitmentMissing = errors.New("signer's commitment is missing from the commitments")
+	// ErrInvalidThresholdResponse is returned when a signer's response does not match its commitment
+	ErrInvalidThresholdResponse = errors.New("invalid threshold response")
+	// ErrInvalidThresholdCommitment is returned when a signer's commitment to the VRF input is not
+	// consistent with its commitment to the base point
+	ErrInvalidThresholdCommitment = errors.New("invalid threshold commitment")
+	// ErrInvalidThresholdOutput is returned when the combined proof does not verify because the
+	// VRF output is not the group's output for the input
+	ErrInvalidThresholdOutput = errors.New("output does not match the group public key")
+)
+
+// ThresholdSignerError is returned when the commitment or response of one signer is invalid.
+// It wraps ErrInvalidThresholdCommitment or ErrInvalidThresholdResponse.
+type ThresholdSignerError struct {
+	Index uint32
+	Err   error
+}
+
+func (e *ThresholdSignerError) Error() string {
+	return fmt.Sprintf("%s from share %d", e.Err, e.Index)
+}
+
+func (e *ThresholdSignerError) Unwrap() error {
+	return e.Err
+}
+
+// ThresholdShare is one member's Shamir share of the secret key of a t-of-n VRF group.
+// The index is the non-zero point at which the sharing polynomial was evaluated.
+type ThresholdShare struct {
+	index uint32
+	key   *SecretKey
+}
+
+// ThresholdPublicKey is the public key of a t-of-n VRF group, together with the threshold and
+// the public key of every share
+type ThresholdPublicKey struct {
+	key       *PublicKey
+	threshold int
+	shares    map[uint32]*PublicKey
+}
+
+// VrfPartialOutput is a member's share of a group VRF output, with a DLEQ proof against the
+// public key of its share
+type VrfPartialOutput struct {
+	index  uint32
+	output *r255.Element
+	proof  *VrfProof
+}
+
+// VrfThresholdNonce is the secret half of a member's first round of threshold proof creation.
+// It must only be used once.
+type VrfThresholdNonce struct {
+	index      uint32
+	d, e       *r255.Scalar
+	commitment *VrfThresholdCommitment
+}
+
+// VrfThresholdCommitment is the public half of a member's first round of threshold proof creation.
+// The proof shows that dh and eh use the same nonces as d and e.
+type VrfThresholdCommitment struct {
+	index  uint32
+	d, e   *r255.Element // d*B, e*B
+	dh, eh *r255.Element // d*H, e*H where H is the VRF input
+	proof  *VrfProof
+}
+
+// VrfThresholdResponse is a member's second round contribution to a threshold proof
+type VrfThresholdResponse struct {
+	index uint32
+	s     *r255.Scalar
+}
+
+// GenerateThresholdKeys generates a new group secret key and splits it into n shares, any
+// threshold of which can create VRF outputs and proofs for the group public key.
+func GenerateThresholdKeys(threshold, n int) (*ThresholdPublicKey, []*ThresholdShare, error) {
+	priv, _, err := GenerateKeypair()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return SplitSecretKey(priv, threshold, n)
+}
+
+// SplitSecretKey splits the secret key into n Shamir shares with indices 1 to n, any threshold
+// of which can create VRF outputs and proofs for the secret key's public key.
+// The split is done by a trusted dealer, who must erase the secret key afterwards.
+func SplitSecretKey(secretKey *SecretKey, threshold, n int) (*ThresholdPublicKey, []*ThresholdShare, error) {
+	if secretKey == nil {
+		return nil, nil, errors.New("secret key provided is nil")
+	}
+
+	if threshold < 1 || threshold > n || uint64(n) > uint64(^uint32(0)) {
+		return nil, nil, ErrInvalidThreshold
+	}
+
+	pub, err := secretKey.Public()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sc, err := ScalarFromBytes(secretKey.key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// f(x) = sc + a_1*x + ... + a_{threshold-1}*x^{threshold-1}
+	coeffs := make([]*r255.Scalar, threshold)
+	coeffs[0] = sc
+	for i := 1; i < threshold; i++ {
+		coeffs[i], err = NewRandomScalar()
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	shares := make([]*ThresholdShare, n)
+	pubs := make(map[uint32]*PublicKey, n)
+	for i := range shares {
+		index := uint32(i + 1)
+		x := scalarFromUint64(uint64(index))
+
+		// evaluate f(index) using Horner's method
+		y := r255.NewScalar().Zero()
+		for k := threshold - 1; k >= 0; k-- {
+			y.Multiply(y, x)
+			y.Add(y, coeffs[k])
+		}
+
+		key := [SecretKeySize]byte{}
+		copy(key[:], y.Encode([]byte{}))
+		nonce := [32]byte{}
+		_, err = rand.Read(nonce[:])
+		if err != nil {
+			return nil, nil, err
+		}
+
+		shares[i] = NewThresholdShare(index, NewSecretKey(key, nonce))
+		pubs[index], err = shares[i].key.Public()
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	gpub, err := NewThresholdPublicKey(pub, threshold, pubs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return gpub, shares, nil
+}
+
+// NewThresholdShare creates a ThresholdShare from its index and secret key
+func NewThresholdShare(index uint32, key *SecretKey) *ThresholdShare {
+	return &ThresholdShare{
+		index: index,
+		key:   key,
+	}
+}
+
+// Index returns the index of the share
+func (s *ThresholdShare) Index() uint32 {
+	return s.index
+}
+
+// SecretKey returns the secret key of the share
+func (s *ThresholdShare) SecretKey() *SecretKey {
+	return s.key
+}
+
+// NewThresholdPublicKey creates a ThresholdPublicKey from the group public key, the threshold
+// and the public key of each share by index
+func NewThresholdPublicKey(key *PublicKey, threshold int, shares map[uint32]*PublicKey) (*ThresholdPublicKey, error) {
+	if key == nil {
+		return nil, errors.New("public key provided is nil")
+	}
+
+	if threshold < 1 || threshold > len(shares) {
+		return nil, ErrInvalidThreshold
+	}
+
+	pubs := make(map[uint32]*PublicKey, len(shares))
+	for index, pub := range shares {
+		if index == 0 || pub == nil {
+			return nil, ErrInvalidShareIndex
+		}
+		pubs[index] = pub
+	}
+
+	return &ThresholdPublicKey{
+		key:       key,
+		threshold: threshold,
+		shares:    pubs,
+	}, nil
+}
+
+// PublicKey returns the group public key, under which combined outputs and proofs verify
+func (g *ThresholdPublicKey) PublicKey() *PublicKey {
+	return g.key
+}
+
+// Threshold returns the number of shares needed to create an output or proof
+func (g *ThresholdPublicKey) Threshold() int {
+	return g.threshold
+}
+
+// SharePublicKey returns the public key of the share with the given index
+func (g *ThresholdPublicKey) SharePublicKey(index uint32) (*PublicKey, error) {
+	pub, has := g.shares[index]
+	if !has {
+		return nil, ErrInvalidShareIndex
+	}
+	return pub, nil
+}
+
+// VrfSignPartial returns this share's partial VRF output for the transcript under the group
+// public key, with a DLEQ proof against the share's public key
+func (s *ThresholdShare) VrfSignPartial(t *merlin.Transcript, group *PublicKey) (*VrfPartialOutput, error) {
+	if t == nil {
+		return nil, errors.New("transcript provided is nil")
+	}
+
+	if group == nil {
+		return nil, errors.New("public key provided is nil")
+	}
+
+	p, err := s.key.vrfCreateInOut(group.vrfHash(t))
+	if err != nil {
+		return nil, err
+	}
+
+	proof, err := s.key.dleqProve(partialTranscript(s.index), p)
+	if err != nil {
+		return nil, err
+	}
+
+	return &VrfPartialOutput{
+		index:  s.index,
+		output: p.output,
+		proof:  proof,
+	}, nil
+}
+
+// VerifyPartial verifies a member's partial VRF output for the transcript
+func (g *ThresholdPublicKey) VerifyPartial(t *merlin.Transcript, partial *VrfPartialOutput) (bool, error) {
+	if t == nil {
+		return false, errors.New("transcript provided is nil")
+	}
+
+	if partial == nil {
+		return false, errors.New("partial output provided is nil")
+	}
+
+	if partial.output == nil || partial.proof == nil {
+		return false, errors.New("partial output provided is incomplete")
+	}
+
+	pub, err := g.SharePublicKey(partial.index)
+	if err != nil {
+		return false, err
+	}
+
+	inout := &VrfInOut{
+		input:  g.key.vrfHash(t),
+		output: partial.output,
+	}
+	return pub.dleqVerify(partialTranscript(partial.index), inout, partial.proof)
+}
+
+// CombinePartials interpolates at least threshold partial outputs into the group VRF output
+// for the transcript. The partial outputs are not verified, callers should check them with
+// VerifyPartial first.
+func (g *ThresholdPublicKey) CombinePartials(t *merlin.Transcript, partials []*VrfPartialOutput) (*VrfInOut, error) {
+	if t == nil {
+		return nil, errors.New("transcript provided is nil")
+	}
+
+	indices := make([]uint32, len(partials))
+	outputs := make([]*r255.Element, len(partials))
+	for i, partial := range partials {
+		if partial == nil {
+			return nil, errors.New("partial output provided is nil")
+		}
+		indices[i] = partial.index
+		outputs[i] = partial.output
+	}
+
+	if err := g.checkSigners(indices); err != nil {
+		return nil, err
+	}
+
+	lambdas := make([]*r255.Scalar, len(indices))
+	for i, index := range indices {
+		lambdas[i] = lagrangeCoefficient(index, indices)
+	}
+
+	return &VrfInOut{
+		input:  g.key.vrfHash(t),
+		output: r255.NewElement().VarTimeMultiScalarMult(lambdas, outputs),
+	}, nil
+}
+
+// VrfThresholdCommit starts this share's part in creating a proof for the combined group VRF
+// output. The commitment is sent to the other signers, and the nonce is kept until
+// VrfThresholdRespond.
+func (s *ThresholdShare) VrfThresholdCommit(inout *VrfInOut) (*VrfThresholdNonce, *VrfThresholdCommitment, error) {
+	if inout == nil {
+		return nil, nil, errors.New("input and output provided is nil")
+	}
+
+	d, err := NewRandomScalar()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	e, err := NewRandomScalar()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	commitment := &VrfThresholdCommitment{
+		index: s.index,
+		d:     r255.NewElement().ScalarBaseMult(d),
+		e:     r255.NewElement().ScalarBaseMult(e),
+		dh:    r255.NewElement().ScalarMult(d, inout.input),
+		eh:    r255.NewElement().ScalarMult(e, inout.input),
+	}
+
+	// prove log_B(D + z*E) = log_H(Dh + z*Eh), which for a random z shows that Dh and Eh use the
+	// nonces of D and E
+	z := commitment.weight(inout.input)
+	w := r255.NewScalar().Multiply(z, e)
+	w.Add(w, d)
+	key := [SecretKeySize]byte{}
+	copy(key[:], w.Encode([]byte{}))
+	_, p := commitment.statement(inout.input, z)
+	commitment.proof, err = NewSecretKey(key, [32]byte{}).dleqProve(commitment.proofTranscript(inout.input), p)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &VrfThresholdNonce{
+		index:      s.index,
+		d:          d,
+		e:          e,
+		commitment: commitment,
+	}, commitment, nil
+}
+
+// VrfThresholdRespond creates this share's response for a proof of the combined group VRF output,
+// given the commitments of every signer. The nonce is erased and cannot be used again.
+func (s *ThresholdShare) VrfThresholdRespond(group *PublicKey, inout *VrfInOut, nonce *VrfThresholdNonce,
+	commitments []*VrfThresholdCommitment) (*VrfThresholdResponse, error) {
+	if group == nil {
+		return nil, errors.New("public key provided is nil")
+	}
+
+	if inout == nil {
+		return nil, errors.New("input and output provided is nil")
+	}
+
+	if nonce == nil {
+		return nil, errors.New("nonce provided is nil")
+	}
+
+	if nonce.d == nil || nonce.e == nil {
+		return nil, ErrThresholdNonceUsed
+	}
+
+	if nonce.index != s.index {
+		return nil, ErrInvalidShareIndex
+	}
+
+	signers, err := sortCommitments(commitments)
+	if err != nil {
+		return nil, err
+	}
+
+	own := sort.Search(len(signers), func(i int) bool { return signers[i].index >= s.index })
+	if own == len(signers) || !signers[own].equal(nonce.commitment) {
+		return nil, ErrThresholdCommitmentMissing
+	}
+
+	rhos, R, hr := thresholdNonceCommitment(group, inout, signers)
+	c := dleqChallenge(merlin.NewTranscript(VRFLabel), group.Encode(), inout, R, hr)
+
+	sc, err := ScalarFromBytes(s.key.key)
+	if err != nil {
+		return nil, err
+	}
+
+	indices := commitmentIndices(signers)
+	lambda := lagrangeCoefficient(s.index, indices)
+
+	// s_i = d_i + rho_i*e_i - c*lambda_i*x_i
+	si := r255.NewScalar().Multiply(rhos[own], nonce.e)
+	si.Add(si, nonce.d)
+	si.Subtract(si, r255.NewScalar().Multiply(c, r255.NewScalar().Multiply(lambda, sc)))
+
+	nonce.d = nil
+	nonce.e = nil
+
+	return &VrfThresholdResponse{
+		index: s.index,
+		s:     si,
+	}, nil
+}
+
+// CombineProof checks every signer's commitment and response and combines them into a proof for
+// the combined group VRF output, which verifies with the group public key's VrfVerify.
+// At least threshold signers must take part, and every signer's commitment must have a response.
+// A faulty signer is reported with a ThresholdSignerError.
+func (g *ThresholdPublicKey) CombineProof(inout *VrfInOut, commitments []*VrfThresholdCommitment,
+	responses []*VrfThresholdResponse) (*VrfProof, error) {
+	if inout == nil {
+		return nil, errors.New("input and output provided is nil")
+	}
+
+	signers, err := sortCommitments(commitments)
+	if err != nil {
+		return nil, err
+	}
+
+	indices := commitmentIndices(signers)
+	if err = g.checkSigners(indices); err != nil {
+		return nil, err
+	}
+
+	if len(responses) != len(signers) {
+		return nil, errors.New("number of responses does not match number of commitments")
+	}
+
+	byIndex := make(map[uint32]*r255.Scalar, len(responses))
+	for _, response := range responses {
+		if response == nil {
+			return nil, errors.New("response provided is nil")
+		}
+		if _, has := byIndex[response.index]; has {
+			return nil, ErrDuplicateShareIndex
+		}
+		byIndex[response.index] = response.s
+	}
+
+	for _, signer := range signers {
+		if !signer.verify(inout.input) {
+			return nil, &ThresholdSignerError{Index: signer.index, Err: ErrInvalidThresholdCommitment}
+		}
+	}
+
+	rhos, R, hr := thresholdNonceCommitment(g.key, inout, signers)
+	c := dleqChallenge(merlin.NewTranscript(VRFLabel), g.key.Encode(), inout, R, hr)
+
+	s := r255.NewScalar().Zero()
+	for i, signer := range signers {
+		si, has := byIndex[signer.index]
+		if !has {
+			return nil, &ThresholdSignerError{Index: signer.index, Err: ErrInvalidThresholdResponse}
+		}
+
+		// s_i*B + c*lambda_i*X_i = D_i + rho_i*E_i
+		cl := r255.NewScalar().Multiply(c, lagrangeCoefficient(signer.index, indices))
+		lhs := r255.NewElement().VarTimeDoubleScalarBaseMult(cl, g.shares[signer.index].key, si)
+		rhs := r255.NewElement().ScalarMult(rhos[i], signer.e)
+		rhs.Add(rhs, signer.d)
+		if lhs.Equal(rhs) != 1 {
+			return nil, &ThresholdSignerError{Index: signer.index, Err: ErrInvalidThresholdResponse}
+		}
+
+		s.Add(s, si)
+	}
+
+	// with every signer checked, the proof only fails if the output was not combined from valid
+	// partial outputs
+	proof := &VrfProof{
+		c: c,
+		s: s,
+	}
+	ok, err := g.key.dleqVerify(merlin.NewTranscript(VRFLabel), inout, proof)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidThresholdOutput
+	}
+
+	return proof, nil
+}
+
+// checkSigners checks that the indices are distinct shares of the group and reach the threshold
+func (g *ThresholdPublicKey) checkSigners(indices []uint32) error {
+	seen := make(map[uint32]struct{}, len(indices))
+	for _, index := range indices {
+		if _, has := g.shares[index]; !has {
+			return ErrInvalidShareIndex
+		}
+		if _, has := seen[index]; has {
+			return ErrDuplicateShareIndex
+		}
+		seen[index] = struct{}{}
+	}
+
+	if len(indices) < g.threshold {
+		return ErrNotEnoughShares
+	}
+
+	return nil
+}
+
+// Index returns the index of the share that created the partial output
+func (p *VrfPartialOutput) Index() uint32 {
+	return p.index
+}
+
+// Output returns the partial output as a VrfOutput
+func (p *VrfPartialOutput) Output() *VrfOutput {
+	return &VrfOutput{
+		output: p.output,
+	}
+}
+
+// Encode returns the encoding of the partial output: the index as u32 LE, the output and the proof
+func (p *VrfPartialOutput) Encode() [VrfPartialOutputSize]byte {
+	enc := [VrfPartialOutputSize]byte{}
+	binary.LittleEndian.PutUint32(enc[:4], p.index)
+	copy(enc[4:36], p.output.Encode([]byte{}))
+	proof := p.proof.Encode()
+	copy(enc[36:], proof[:])
+	return enc
+}
+
+// Decode sets the VrfPartialOutput to the decoded input
+func (p *VrfPartialOutput) Decode(in [VrfPartialOutputSize]byte) error {
+	output := r255.NewElement()
+	err := output.Decode(in[4:36])
+	if err != nil {
+		return err
+	}
+
+	proof := [64]byte{}
+	copy(proof[:], in[36:])
+	p.proof = new(VrfProof)
+	err = p.proof.Decode(proof)
+	if err != nil {
+		return err
+	}
+
+	p.index = binary.LittleEndian.Uint32(in[:4])
+	p.output = output
+	return nil
+}
+
+// Index returns the index of the share that created the commitment
+func (c *VrfThresholdCommitment) Index() uint32 {
+	return c.index
+}
+
+// Encode returns the encoding of the commitment: the index as u32 LE followed by its four points
+// and its proof
+func (c *VrfThresholdCommitment) Encode() [VrfThresholdCommitmentSize]byte {
+	enc := [VrfThresholdCommitmentSize]byte{}
+	binary.LittleEndian.PutUint32(enc[:4], c.index)
+	for i, p := range []*r255.Element{c.d, c.e, c.dh, c.eh} {
+		copy(enc[4+32*i:], p.Encode([]byte{}))
+	}
+	proof := c.proof.Encode()
+	copy(enc[4+4*32:], proof[:])
+	return enc
+}
+
+// Decode sets the VrfThresholdCommitment to the decoded input
+func (c *VrfThresholdCommitment) Decode(in [VrfThresholdCommitmentSize]byte) error {
+	points := make([]*r255.Element, 4)
+	for i := range points {
+		points[i] = r255.NewElement()
+		err := points[i].Decode(in[4+32*i : 4+32*(i+1)])
+		if err != nil {
+			return err
+		}
+	}
+
+	proof := [64]byte{}
+	copy(proof[:], in[4+4*32:])
+	p := new(VrfProof)
+	err := p.Decode(proof)
+	if err != nil {
+		return err
+	}
+
+	c.index = binary.LittleEndian.Uint32(in[:4])
+	c.d, c.e, c.dh, c.eh = points[0], points[1], points[2], points[3]
+	c.proof = p
+	return nil
+}
+
+func (c *VrfThresholdCommitment) equal(other *VrfThresholdCommitment) bool {
+	return c.Encode() == other.Encode()
+}
+
+// verify checks the commitment's proof that Dh and Eh use the nonces of D and E for the input H
+func (c *VrfThresholdCommitment) verify(input *r255.Element) bool {
+	pub, p := c.statement(input, c.weight(input))
+	ok, err := pub.dleqVerify(c.proofTranscript(input), p, c.proof)
+	return err == nil && ok
+}
+
+// weight returns the scalar z that binds the commitment's points, for the statement
+// log_B(D + z*E) = log_H(Dh + z*Eh) of its proof
+func (c *VrfThresholdCommitment) weight(input *r255.Element) *r255.Scalar {
+	t := c.proofTranscript(input)
+	for _, p := range []*r255.Element{c.d, c.e, c.dh, c.eh} {
+		t.AppendMessage([]byte("commitment"), p.Encode([]byte{}))
+	}
+	return challengeScalar(t, []byte("weight"))
+}
+
+// statement returns D + z*E as the public key, and H and Dh + z*Eh as the input and output, of
+// the commitment's proof
+func (c *VrfThresholdCommitment) statement(input *r255.Element, z *r255.Scalar) (*PublicKey, *VrfInOut) {
+	pub := &PublicKey{
+		key: r255.NewElement().Add(c.d, r255.NewElement().ScalarMult(z, c.e)),
+	}
+	p := &VrfInOut{
+		input:  input,
+		output: r255.NewElement().Add(c.dh, r255.NewElement().ScalarMult(z, c.eh)),
+	}
+	return pub, p
+}
+
+// proofTranscript returns the transcript a commitment's proof is created on, which binds the
+// proof to the share index
+func (c *VrfThresholdCommitment) proofTranscript(input *r255.Element) *merlin.Transcript {
+	t := merlin.NewTranscript("ThresholdVRFCommitment")
+	idx := [4]byte{}
+	binary.LittleEndian.PutUint32(idx[:], c.index)
+	t.AppendMessage([]byte("vrf:share"), idx[:])
+	t.AppendMessage([]byte("vrf:h"), input.Encode([]byte{}))
+	return t
+}
+
+// Index returns the index of the share that created the response
+func (r *VrfThresholdResponse) Index() uint32 {
+	return r.index
+}
+
+// Encode returns the encoding of the response: the index as u32 LE followed by the scalar
+func (r *VrfThresholdResponse) Encode() [VrfThresholdResponseSize]byte {
+	enc := [VrfThresholdResponseSize]byte{}
+	binary.LittleEndian.PutUint32(enc[:4], r.index)
+	copy(enc[4:], r.s.Encode([]byte{}))
+	return enc
+}
+
+// Decode sets the VrfThresholdResponse to the decoded input
+func (r *VrfThresholdResponse) Decode(in [VrfThresholdResponseSize]byte) error {
+	s := r255.NewScalar()
+	err := s.Decode(in[4:])
+	if err != nil {
+		return err
+	}
+
+	r.index = binary.LittleEndian.Uint32(in[:4])
+	r.s = s
+	return nil
+}
+
+// partialTranscript returns the transcript a partial output's proof is created on, which binds
+// the proof to the share index
+func partialTranscript(index uint32) *merlin.Transcript {
+	t := merlin.NewTranscript(VRFLabel)
+	idx := [4]byte{}
+	binary.LittleEndian.PutUint32(idx[:], index)
+	t.AppendMessage([]byte("vrf:share"), idx[:])
+	return t
+}
+
+// sortCommitments returns a copy of the commitments sorted by index, checking for duplicates
+func sortCommitments(commitments []*VrfThresholdCommitment) ([]*VrfThresholdCommitment, error) {
+	sorted := make([]*VrfThresholdCommitment, len(commitments))
+	for i, c := range commitments {
+		if c == nil {
+			return nil, errors.New("commitment provided is nil")
+		}
+		if c.d == nil || c.e == nil || c.dh == nil || c.eh == nil || c.proof == nil {
+			return nil, errors.New("commitment provided is incomplete")
+		}
+		sorted[i] = c
+	}
+
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].index < sorted[j].index })
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].index == sorted[i-1].index {
+			return nil, ErrDuplicateShareIndex
+		}
+	}
+
+	return sorted, nil
+}
+
+func commitmentIndices(commitments []*VrfThresholdCommitment) []uint32 {
+	indices := make([]uint32, len(commitments))
+	for i, c := range commitments {
+		indices[i] = c.index
+	}
+	return indices
+}
+
+// thresholdNonceCommitment returns the binding factor of each signer, and the aggregate nonce
+// commitments R = sum(D_i + rho_i*E_i) and hr = sum(Dh_i + rho_i*Eh_i). Binding each signer's
+// nonces to the whole signing set prevents other signers from choosing their commitments
+// adaptively across concurrent sessions.
+func thresholdNonceCommitment(group *PublicKey, inout *VrfInOut, signers []*VrfThresholdCommitment) ([]*r255.Scalar, *r255.Element, *r255.Element) {
+	t := merlin.NewTranscript("ThresholdVRF")
+	pubenc := group.Encode()
+	t.AppendMessage([]byte("vrf:pk"), pubenc[:])
+	t.AppendMessage([]byte("vrf:h"), inout.input.Encode([]byte{}))
+	t.AppendMessage([]byte("vrf:h^sk"), inout.output.Encode([]byte{}))
+	for _, c := range signers {
+		enc := c.Encode()
+		t.AppendMessage([]byte("commitment"), enc[:])
+	}
+
+	one := scalarFromUint64(1)
+	rhos := make([]*r255.Scalar, len(signers))
+	scalars := make([]*r255.Scalar, 0, 2*len(signers))
+	points := make([]*r255.Element, 0, 2*len(signers))
+	hpoints := make([]*r255.Element, 0, 2*len(signers))
+	for i, c := range signers {
+		idx := [4]byte{}
+		binary.LittleEndian.PutUint32(idx[:], c.index)
+		t.AppendMessage([]byte("signer"), idx[:])
+		rhos[i] = challengeScalar(t, []byte("binding"))
+
+		scalars = append(scalars, one, rhos[i])
+		points = append(points, c.d, c.e)
+		hpoints = append(hpoints, c.dh, c.eh)
+	}
+
+	R := r255.NewElement().VarTimeMultiScalarMult(scalars, points)
+	hr := r255.NewElement().VarTimeMultiScalarMult(scalars, hpoints)
+	return rhos, R, hr
+}
+
+// lagrangeCoefficient returns the Lagrange coefficient at zero of the index within the indices
+func lagrangeCoefficient(index uint32, indices []uint32) *r255.Scalar {
+	num := scalarFromUint64(1)
+	den := scalarFromUint64(1)
+	xi := scalarFromUint64(uint64(index))
+	for _, j := range indices {
+		if j == index {
+			continue
+		}
+		xj := scalarFromUint64(uint64(j))
+		num.Multiply(num, xj)
+		den.Multiply(den, r255.NewScalar().Subtract(xj, xi))
+	}
+
+	return num.Multiply(num, den.Invert(den))
+}
+
+func scalarFromUint64(n uint64) *r255.Scalar {
+	b := [32]byte{}
+	binary.LittleEndian.PutUint64(b[:8], n)
+	s := r255.NewScalar()
+	// a 64-bit integer is always a canonical scalar
+	_ = s.Decode(b[:])
+	return s
+}
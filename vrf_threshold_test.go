@@ -0,0 +1,247 @@
+package schnorrkel
+
+import (
+	"testing"
+
+	"github.com/gtank/merlin"
+	r255 "github.com/gtank/ristretto255"
+	"github.com/stretchr/testify/require"
+)
+
+func signThresholdPartials(t *testing.T, group *ThresholdPublicKey, shares []*ThresholdShare) []*VrfPartialOutput {
+	partials := make([]*VrfPartialOutput, len(shares))
+	for i, share := range shares {
+		partial, err := share.VrfSignPartial(merlin.NewTranscript("beacon"), group.PublicKey())
+		require.NoError(t, err)
+
+		ok, err := group.VerifyPartial(merlin.NewTranscript("beacon"), partial)
+		require.NoError(t, err)
+		require.True(t, ok)
+		partials[i] = partial
+	}
+	return partials
+}
+
+func createThresholdProof(t *testing.T, group *ThresholdPublicKey, shares []*ThresholdShare, inout *VrfInOut) *VrfProof {
+	nonces := make([]*VrfThresholdNonce, len(shares))
+	commitments := make([]*VrfThresholdCommitment, len(shares))
+	for i, share := range shares {
+		var err error
+		nonces[i], commitments[i], err = share.VrfThresholdCommit(inout)
+		require.NoError(t, err)
+	}
+
+	responses := make([]*VrfThresholdResponse, len(shares))
+	for i, share := range shares {
+		var err error
+		responses[i], err = share.VrfThresholdRespond(group.PublicKey(), inout, nonces[i], commitments)
+		require.NoError(t, err)
+	}
+
+	proof, err := group.CombineProof(inout, commitments, responses)
+	require.NoError(t, err)
+	return proof
+}
+
+func TestThresholdVrf(t *testing.T) {
+	priv, pub, err := GenerateKeypair()
+	require.NoError(t, err)
+
+	group, shares, err := SplitSecretKey(priv, 3, 5)
+	require.NoError(t, err)
+	require.Equal(t, 3, group.Threshold())
+	require.Equal(t, pub.Encode(), group.PublicKey().Encode())
+
+	expected, err := priv.VrfCreateHash(merlin.NewTranscript("beacon"))
+	require.NoError(t, err)
+
+	// any 3 of the 5 shares combine into the same output as the group secret key
+	for _, subset := range [][]int{{0, 1, 2}, {4, 2, 0}, {1, 3, 4}, {0, 1, 2, 3, 4}} {
+		signers := make([]*ThresholdShare, len(subset))
+		for i, j := range subset {
+			signers[i] = shares[j]
+		}
+
+		partials := signThresholdPartials(t, group, signers)
+		inout, err := group.CombinePartials(merlin.NewTranscript("beacon"), partials)
+		require.NoError(t, err)
+		require.Equal(t, expected.Encode(), inout.Encode())
+
+		proof := createThresholdProof(t, group, signers, inout)
+		ok, err := pub.VrfVerify(merlin.NewTranscript("beacon"), inout.Output(), proof)
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+}
+
+func TestThresholdVrf_NotEnoughShares(t *testing.T) {
+	group, shares, err := GenerateThresholdKeys(3, 5)
+	require.NoError(t, err)
+
+	partials := signThresholdPartials(t, group, shares[:2])
+	_, err = group.CombinePartials(merlin.NewTranscript("beacon"), partials)
+	require.ErrorIs(t, err, ErrNotEnoughShares)
+
+	_, err = group.CombinePartials(merlin.NewTranscript("beacon"), append(partials, partials[0]))
+	require.ErrorIs(t, err, ErrDuplicateShareIndex)
+
+	_, _, err = GenerateThresholdKeys(6, 5)
+	require.ErrorIs(t, err, ErrInvalidThreshold)
+	_, _, err = GenerateThresholdKeys(0, 5)
+	require.ErrorIs(t, err, ErrInvalidThreshold)
+}
+
+func TestThresholdVrf_BadPartial(t *testing.T) {
+	group, shares, err := GenerateThresholdKeys(2, 3)
+	require.NoError(t, err)
+
+	partial, err := shares[0].VrfSignPartial(merlin.NewTranscript("beacon"), group.PublicKey())
+	require.NoError(t, err)
+
+	ok, err := group.VerifyPartial(merlin.NewTranscript("other"), partial)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// a partial output claiming another share's index does not verify
+	enc := partial.Encode()
+	enc[0] = 2
+	claimed := new(VrfPartialOutput)
+	require.NoError(t, claimed.Decode(enc))
+	require.Equal(t, uint32(2), claimed.Index())
+	ok, err = group.VerifyPartial(merlin.NewTranscript("beacon"), claimed)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	enc[0] = 9
+	require.NoError(t, claimed.Decode(enc))
+	_, err = group.VerifyPartial(merlin.NewTranscript("beacon"), claimed)
+	require.ErrorIs(t, err, ErrInvalidShareIndex)
+}
+
+func TestThresholdVrf_NilPartial(t *testing.T) {
+	group, _, err := GenerateThresholdKeys(2, 3)
+	require.NoError(t, err)
+
+	for _, partial := range []*VrfPartialOutput{nil, {index: 1}, {index: 1, output: r255.NewElement()}} {
+		_, err = group.VerifyPartial(merlin.NewTranscript("beacon"), partial)
+		require.Error(t, err)
+	}
+}
+
+func TestThresholdVrf_TamperedCommitment(t *testing.T) {
+	group, shares, err := GenerateThresholdKeys(2, 3)
+	require.NoError(t, err)
+
+	inout, err := group.CombinePartials(merlin.NewTranscript("beacon"), signThresholdPartials(t, group, shares[:2]))
+	require.NoError(t, err)
+
+	nonces := make([]*VrfThresholdNonce, 2)
+	commitments := make([]*VrfThresholdCommitment, 2)
+	for i, share := range shares[:2] {
+		nonces[i], commitments[i], err = share.VrfThresholdCommit(inout)
+		require.NoError(t, err)
+	}
+
+	// share 2 shifts its commitment to the VRF input without changing the one to the base point
+	commitments[1].dh.Add(commitments[1].dh, r255.NewElement().Base())
+
+	responses := make([]*VrfThresholdResponse, 2)
+	for i, share := range shares[:2] {
+		responses[i], err = share.VrfThresholdRespond(group.PublicKey(), inout, nonces[i], commitments)
+		require.NoError(t, err)
+	}
+
+	_, err = group.CombineProof(inout, commitments, responses)
+	require.ErrorIs(t, err, ErrInvalidThresholdCommitment)
+	var signerErr *ThresholdSignerError
+	require.ErrorAs(t, err, &signerErr)
+	require.Equal(t, uint32(2), signerErr.Index)
+}
+
+func TestThresholdVrf_WrongOutput(t *testing.T) {
+	group, shares, err := GenerateThresholdKeys(2, 3)
+	require.NoError(t, err)
+
+	inout, err := group.CombinePartials(merlin.NewTranscript("beacon"), signThresholdPartials(t, group, shares[:2]))
+	require.NoError(t, err)
+	inout.output = r255.NewElement().Add(inout.output, r255.NewElement().Base())
+
+	nonces := make([]*VrfThresholdNonce, 2)
+	commitments := make([]*VrfThresholdCommitment, 2)
+	for i, share := range shares[:2] {
+		nonces[i], commitments[i], err = share.VrfThresholdCommit(inout)
+		require.NoError(t, err)
+	}
+
+	responses := make([]*VrfThresholdResponse, 2)
+	for i, share := range shares[:2] {
+		responses[i], err = share.VrfThresholdRespond(group.PublicKey(), inout, nonces[i], commitments)
+		require.NoError(t, err)
+	}
+
+	_, err = group.CombineProof(inout, commitments, responses)
+	require.ErrorIs(t, err, ErrInvalidThresholdOutput)
+}
+
+func TestThresholdVrf_BadResponse(t *testing.T) {
+	group, shares, err := GenerateThresholdKeys(2, 3)
+	require.NoError(t, err)
+
+	inout, err := group.CombinePartials(merlin.NewTranscript("beacon"), signThresholdPartials(t, group, shares[:2]))
+	require.NoError(t, err)
+
+	nonce0, commitment0, err := shares[0].VrfThresholdCommit(inout)
+	require.NoError(t, err)
+	nonce1, commitment1, err := shares[1].VrfThresholdCommit(inout)
+	require.NoError(t, err)
+	commitments := []*VrfThresholdCommitment{commitment0, commitment1}
+
+	response0, err := shares[0].VrfThresholdRespond(group.PublicKey(), inout, nonce0, commitments)
+	require.NoError(t, err)
+
+	_, err = shares[0].VrfThresholdRespond(group.PublicKey(), inout, nonce0, commitments)
+	require.ErrorIs(t, err, ErrThresholdNonceUsed)
+
+	_, err = shares[1].VrfThresholdRespond(group.PublicKey(), inout, nonce1, commitments[:1])
+	require.ErrorIs(t, err, ErrThresholdCommitmentMissing)
+
+	response1, err := shares[1].VrfThresholdRespond(group.PublicKey(), inout, nonce1, commitments)
+	require.NoError(t, err)
+
+	bad := response1.Encode()
+	bad[4] ^= 1
+	badResponse := new(VrfThresholdResponse)
+	require.NoError(t, badResponse.Decode(bad))
+	_, err = group.CombineProof(inout, commitments, []*VrfThresholdResponse{response0, badResponse})
+	require.ErrorIs(t, err, ErrInvalidThresholdResponse)
+	var signerErr *ThresholdSignerError
+	require.ErrorAs(t, err, &signerErr)
+	require.Equal(t, uint32(2), signerErr.Index)
+
+	proof, err := group.CombineProof(inout, commitments, []*VrfThresholdResponse{response1, response0})
+	require.NoError(t, err)
+	ok, err := group.PublicKey().VrfVerify(merlin.NewTranscript("beacon"), inout.Output(), proof)
+	require.NoError(t, err)
+	require.True(t, ok)
+}
+
+func TestThresholdVrf_Encode(t *testing.T) {
+	group, shares, err := GenerateThresholdKeys(2, 2)
+	require.NoError(t, err)
+
+	partial, err := shares[1].VrfSignPartial(merlin.NewTranscript("beacon"), group.PublicKey())
+	require.NoError(t, err)
+	decPartial := new(VrfPartialOutput)
+	require.NoError(t, decPartial.Decode(partial.Encode()))
+	require.Equal(t, partial.Encode(), decPartial.Encode())
+
+	inout, err := group.CombinePartials(merlin.NewTranscript("beacon"), signThresholdPartials(t, group, shares))
+	require.NoError(t, err)
+	_, commitment, err := shares[0].VrfThresholdCommit(inout)
+	require.NoError(t, err)
+	decCommitment := new(VrfThresholdCommitment)
+	require.NoError(t, decCommitment.Decode(commitment.Encode()))
+	require.True(t, commitment.equal(decCommitment))
+	require.True(t, decCommitment.verify(inout.input))
+	require.Equal(t, uint32(1), decCommitment.Index())
+}